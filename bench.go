@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/leuan/leumusic-downloader/leumusic"
+)
+
+// benchBatchSize is the batch size the bench command compares against when
+// --batch isn't set.
+const benchBatchSize = 8
+
+// benchmark downloads the same songs twice into throwaway folders, once
+// with a yt-dlp process per song and once in batches, and prints how long
+// each took. Nothing is recorded in the ledger.
+func benchmark(path string, lines []string) int {
+	if len(lines) == 0 {
+		var err error
+		if lines, err = readSongLines(path); err != nil {
+			fmt.Println(tr("file.notFound", path))
+			return exitUsageError
+		}
+	}
+
+	var tasks []leumusic.DownloadTask
+	for _, line := range lines {
+		if task := leumusic.ParseLine(line); task != nil {
+			tasks = append(tasks, *task)
+		}
+	}
+	if len(tasks) == 0 {
+		fmt.Println(tr("bench.noSongs"))
+		return exitUsageError
+	}
+
+	size := batchSize
+	if size <= 1 {
+		size = benchBatchSize
+	}
+	fmt.Println(trn("bench.starting", len(tasks), len(tasks), size))
+
+	single, singleOK, err := benchRun(tasks, 0)
+	if err != nil {
+		fmt.Println(tr("download.error", err))
+		return exitTotalFailure
+	}
+	fmt.Println(tr("bench.single", single.Round(time.Millisecond), perSong(single, len(tasks)), singleOK, len(tasks)))
+
+	batched, batchedOK, err := benchRun(tasks, size)
+	if err != nil {
+		fmt.Println(tr("download.error", err))
+		return exitTotalFailure
+	}
+	fmt.Println(tr("bench.batched", size, batched.Round(time.Millisecond), perSong(batched, len(tasks)), batchedOK, len(tasks)))
+
+	if batched > 0 {
+		fmt.Println(tr("bench.speedup", float64(single)/float64(batched)))
+	}
+	return exitOK
+}
+
+// benchRun downloads tasks into a temporary folder that is removed
+// afterwards, and returns the wall time and how many songs succeeded.
+func benchRun(tasks []leumusic.DownloadTask, batch int) (time.Duration, int, error) {
+	dir, err := os.MkdirTemp("", "leumusic-bench-")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer os.RemoveAll(dir)
+
+	var succeeded int32
+	opts := downloaderOptions(func(e leumusic.Event) {
+		switch e.Kind {
+		case leumusic.EventSucceeded:
+			atomic.AddInt32(&succeeded, 1)
+		case leumusic.EventFailed:
+			fmt.Println(failureMessage(e.Task, e.Err))
+		}
+	})
+	// Every run downloads into a folder of its own, and nothing is skipped
+	// as already downloaded, so the runs compare.
+	opts.OutputDir, opts.BatchSize, opts.Ledger = dir, batch, nil
+	downloader := leumusic.New(opts)
+
+	start := time.Now()
+	downloader.Download(context.Background(), tasks)
+	return time.Since(start), int(succeeded), nil
+}
+
+func perSong(total time.Duration, songs int) time.Duration {
+	return (total / time.Duration(songs)).Round(time.Millisecond)
+}
+
+// readSongLines returns the non-empty, non-comment lines of a songs file.
+func readSongLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/leuan/leumusic-downloader/leumusic"
+)
+
+const (
+	exitOK             = 0
+	exitPartialFailure = 1
+	exitTotalFailure   = 2
+	exitUsageError     = 3
+	// exitTimedOut and exitInterrupted are what timeout(1) and shells use
+	// for a run stopped by run_timeout or Ctrl+C.
+	exitTimedOut    = 124
+	exitInterrupted = 130
+)
+
+var workersOverride int
+
+func exitCodeFor(stats DownloadStats) int {
+	switch {
+	case stats.timedOut:
+		return exitTimedOut
+	case stats.interrupted > 0:
+		return exitInterrupted
+	case stats.failed == 0:
+		return exitOK
+	case stats.success == 0:
+		return exitTotalFailure
+	default:
+		return exitPartialFailure
+	}
+}
+
+func cliUsage() string {
+	return tr("cli.usage", songsFileName(), strings.Join(availableLanguages(), ", "))
+}
+
+// extractLangFlag removes --lang from anywhere in the arguments so it also
+// works for the interactive menu, where no subcommand is given.
+func extractLangFlag(args []string) (string, []string) {
+	var lang string
+	rest := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "lang" {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		lang = value
+	}
+
+	return lang, rest
+}
+
+func durationFlag(target *string) func(string) error {
+	return func(v string) error {
+		if _, err := leumusic.ParseDuration(v); err != nil {
+			return err
+		}
+		*target = v
+		return nil
+	}
+}
+
+func runCLI(args []string) int {
+	command, rest := args[0], args[1:]
+
+	switch command {
+	case "help", "-h", "-help", "--help":
+		fmt.Print(cliUsage())
+		return exitOK
+	}
+
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, cliUsage()) }
+
+	songsPath := songsFileName()
+	if command == "download" || command == "bench" {
+		fs.StringVar(&songsPath, "file", songsPath, "")
+	}
+	var withParked bool
+	if command == "retry" {
+		fs.BoolVar(&withParked, "parked", false, "")
+	}
+	var dryRun bool
+	if command == "sync" {
+		fs.BoolVar(&dryRun, "dry-run", false, "")
+	}
+	fs.BoolVar(&turboMode, "turbo", turboMode, "")
+	fs.BoolVar(&qualityMode, "quality", qualityMode, "")
+	fs.BoolVar(&useCookies, "cookies", useCookies, "")
+	fs.Func("cookie-source", "", func(v string) error {
+		if _, err := leumusic.ParseCookieSource(v); err != nil {
+			return err
+		}
+		cookieSource = v
+		return nil
+	})
+	fs.BoolFunc("no-auto-cookies", "", func(v string) error {
+		off, err := strconv.ParseBool(v)
+		autoCookies = !off
+		return err
+	})
+	fs.IntVar(&workersOverride, "workers", workersOverride, "")
+	fs.IntVar(&batchSize, "batch", batchSize, "")
+	fs.Func("include", "", func(v string) error {
+		includeWords = splitList(v)
+		return nil
+	})
+	fs.Func("exclude", "", func(v string) error {
+		words := splitList(v)
+		excludeWords = &words
+		return nil
+	})
+	fs.Func("providers", "", func(v string) error {
+		list := splitList(v)
+		if err := validateProviders(list); err != nil {
+			return err
+		}
+		providers = list
+		return nil
+	})
+	fs.Func("min-duration", "", durationFlag(&minDuration))
+	fs.Func("max-duration", "", durationFlag(&maxDuration))
+	fs.Func("task-timeout", "", durationFlag(&taskTimeout))
+	fs.Func("run-timeout", "", durationFlag(&runTimeout))
+
+	var positional []string
+	for {
+		if err := fs.Parse(rest); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return exitOK
+			}
+			return exitUsageError
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		rest = fs.Args()[1:]
+	}
+
+	if turboMode && qualityMode {
+		fmt.Fprintln(os.Stderr, tr("cli.turboQuality"))
+		return exitUsageError
+	}
+	if workersOverride < 0 {
+		fmt.Fprintln(os.Stderr, tr("cli.workers"))
+		return exitUsageError
+	}
+	if batchSize < 0 {
+		fmt.Fprintln(os.Stderr, tr("cli.batch"))
+		return exitUsageError
+	}
+
+	switch command {
+	case "download":
+		stats, err := downloadFromFile(songsPath)
+		if err != nil {
+			return exitUsageError
+		}
+		return exitCodeFor(stats)
+	case "add":
+		if len(positional) == 0 {
+			return exitCodeFor(manualInput())
+		}
+		return exitCodeFor(addSongs(positional))
+	case "list":
+		showDownloadedSongs()
+	case "check":
+		if !checkRequiredTools() {
+			return exitTotalFailure
+		}
+	case "tree":
+		showFolderStructure()
+	case "resume":
+		return exitCodeFor(resumeSession())
+	case "retry":
+		return exitCodeFor(retryFailed(withParked))
+	case "sync":
+		stats, err := syncPlaylists(positional, dryRun)
+		if err != nil {
+			return exitUsageError
+		}
+		return exitCodeFor(stats)
+	case "bench":
+		if !checkRequiredTools() {
+			return exitTotalFailure
+		}
+		return benchmark(songsPath, positional)
+	default:
+		fmt.Fprint(os.Stderr, cliUsage())
+		return exitUsageError
+	}
+
+	return exitOK
+}
@@ -0,0 +1,478 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/leuan/leumusic-downloader/leumusic"
+)
+
+const projectConfigFile = "leumusic.json"
+
+// Config holds every persisted setting. Sources are applied in this order,
+// each one overriding the previous: defaults, user config file, project
+// config file (./leumusic.json), LEUMUSIC_* environment variables, CLI flags.
+type Config struct {
+	UseCookies     bool    `json:"use_cookies"`
+	TurboMode      bool    `json:"turbo_mode"`
+	QualityMode    bool    `json:"quality_mode"`
+	Workers        int     `json:"workers"`
+	YtDlpPath      string  `json:"ytdlp_path,omitempty"`
+	FFmpegPath     string  `json:"ffmpeg_path,omitempty"`
+	Language       string  `json:"language,omitempty"`
+	MatchThreshold float64 `json:"match_threshold,omitempty"`
+	// AutoCookies retries bot-checked and age-restricted downloads with
+	// browser cookies. Unset means on.
+	AutoCookies *bool `json:"auto_cookies,omitempty"`
+	// CookieSource is a browser spec such as "firefox:default-release",
+	// a cookies.txt path, or "none"; see leumusic.ParseCookieSource.
+	CookieSource string `json:"cookie_source,omitempty"`
+	// SiteCookies overrides CookieSource for some domains.
+	SiteCookies   map[string]string `json:"site_cookies,omitempty"`
+	SearchResults int               `json:"search_results,omitempty"`
+	// BatchSize above 1 fetches that many songs per yt-dlp process.
+	BatchSize    int       `json:"batch_size,omitempty"`
+	Providers    []string  `json:"providers,omitempty"`
+	IncludeWords []string  `json:"include_words,omitempty"`
+	ExcludeWords *[]string `json:"exclude_words,omitempty"`
+	MinDuration  string    `json:"min_duration,omitempty"`
+	MaxDuration  string    `json:"max_duration,omitempty"`
+	// TaskTimeout limits one song and RunTimeout a whole run, such as
+	// "20m" or "2h". An unset TaskTimeout uses the library default; "0"
+	// means no limit.
+	TaskTimeout string `json:"task_timeout,omitempty"`
+	RunTimeout  string `json:"run_timeout,omitempty"`
+	// ParkAfter is how many runs in a row a song may fail before retries
+	// skip it. Unset uses the library default; negative never parks.
+	ParkAfter int `json:"park_after,omitempty"`
+	// SyncRemoved is what sync does with the songs removed from a playlist
+	// upstream: "archive" (the default) moves them to the playlist's
+	// archive folder, "delete" deletes them.
+	SyncRemoved string `json:"sync_removed,omitempty"`
+	// RetryPolicies overrides the retry policy of some error kinds, keyed
+	// by leumusic.ErrorKind names such as "rate-limited".
+	RetryPolicies map[string]RetryConfig `json:"retry_policies,omitempty"`
+	// Throttle overrides how fast yt-dlp is run and when all downloads
+	// pause because of blocking.
+	Throttle *ThrottleConfig `json:"throttle,omitempty"`
+}
+
+// RetryConfig is the JSON form of a leumusic.RetryPolicy.
+type RetryConfig struct {
+	Retries    int    `json:"retries"`
+	Backoff    string `json:"backoff,omitempty"`
+	MaxBackoff string `json:"max_backoff,omitempty"`
+	GiveUp     bool   `json:"give_up,omitempty"`
+}
+
+// ThrottleConfig is the JSON form of a leumusic.Throttle. Zero fields keep
+// the defaults; a negative rate or break_after turns that part off.
+type ThrottleConfig struct {
+	Rate       float64 `json:"rate,omitempty"`
+	Burst      int     `json:"burst,omitempty"`
+	Jitter     string  `json:"jitter,omitempty"`
+	BreakAfter int     `json:"break_after,omitempty"`
+	Cooldown   string  `json:"cooldown,omitempty"`
+}
+
+func userConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "leumusic", "config.json"), nil
+}
+
+func loadConfig() (Config, error) {
+	var cfg Config
+	var errs []error
+
+	if path, err := userConfigPath(); err == nil {
+		errs = append(errs, mergeConfigFile(&cfg, path))
+	}
+	errs = append(errs, mergeConfigFile(&cfg, projectConfigFile))
+	errs = append(errs, mergeConfigEnv(&cfg))
+
+	if cfg.TurboMode && cfg.QualityMode {
+		cfg.QualityMode = false
+	}
+	if err := validateProviders(cfg.Providers); err != nil {
+		errs = append(errs, err)
+		cfg.Providers = nil
+	}
+	if _, err := leumusic.ParseCookieSource(cfg.CookieSource); err != nil {
+		errs = append(errs, fmt.Errorf("cookie_source: %w", err))
+		cfg.CookieSource = ""
+	}
+	for site, spec := range cfg.SiteCookies {
+		if _, err := leumusic.ParseCookieSource(spec); err != nil {
+			errs = append(errs, fmt.Errorf("site_cookies.%s: %w", site, err))
+			delete(cfg.SiteCookies, site)
+		}
+	}
+	if _, err := throttle(cfg.Throttle); err != nil {
+		errs = append(errs, err)
+		cfg.Throttle = nil
+	}
+	for name, rc := range cfg.RetryPolicies {
+		if _, err := retryPolicy(name, rc); err != nil {
+			errs = append(errs, err)
+			delete(cfg.RetryPolicies, name)
+		}
+	}
+	for _, d := range []*string{&cfg.MinDuration, &cfg.MaxDuration, &cfg.TaskTimeout, &cfg.RunTimeout} {
+		if _, err := leumusic.ParseDuration(*d); *d != "" && err != nil {
+			errs = append(errs, err)
+			*d = ""
+		}
+	}
+	if err := validateSyncRemoved(cfg.SyncRemoved); err != nil {
+		errs = append(errs, err)
+		cfg.SyncRemoved = ""
+	}
+	return cfg, errors.Join(errs...)
+}
+
+func mergeConfigFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func mergeConfigEnv(cfg *Config) error {
+	var errs []error
+
+	bools := []struct {
+		name  string
+		value *bool
+	}{
+		{"LEUMUSIC_COOKIES", &cfg.UseCookies},
+		{"LEUMUSIC_TURBO", &cfg.TurboMode},
+		{"LEUMUSIC_QUALITY", &cfg.QualityMode},
+	}
+	for _, b := range bools {
+		if raw, ok := os.LookupEnv(b.name); ok {
+			v, err := strconv.ParseBool(raw)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", b.name, err))
+				continue
+			}
+			*b.value = v
+		}
+	}
+
+	if raw, ok := os.LookupEnv("LEUMUSIC_AUTO_COOKIES"); ok {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("LEUMUSIC_AUTO_COOKIES: %w", err))
+		} else {
+			cfg.AutoCookies = &v
+		}
+	}
+	if raw, ok := os.LookupEnv("LEUMUSIC_WORKERS"); ok {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			errs = append(errs, fmt.Errorf("LEUMUSIC_WORKERS: invalid value %q", raw))
+		} else {
+			cfg.Workers = v
+		}
+	}
+	if raw, ok := os.LookupEnv("LEUMUSIC_SEARCH_RESULTS"); ok {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			errs = append(errs, fmt.Errorf("LEUMUSIC_SEARCH_RESULTS: invalid value %q", raw))
+		} else {
+			cfg.SearchResults = v
+		}
+	}
+	if raw, ok := os.LookupEnv("LEUMUSIC_BATCH_SIZE"); ok {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			errs = append(errs, fmt.Errorf("LEUMUSIC_BATCH_SIZE: invalid value %q", raw))
+		} else {
+			cfg.BatchSize = v
+		}
+	}
+	if raw, ok := os.LookupEnv("LEUMUSIC_PARK_AFTER"); ok {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("LEUMUSIC_PARK_AFTER: invalid value %q", raw))
+		} else {
+			cfg.ParkAfter = v
+		}
+	}
+	if raw, ok := os.LookupEnv("LEUMUSIC_MATCH_THRESHOLD"); ok {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 || v > 1 {
+			errs = append(errs, fmt.Errorf("LEUMUSIC_MATCH_THRESHOLD: invalid value %q", raw))
+		} else {
+			cfg.MatchThreshold = v
+		}
+	}
+	if v := os.Getenv("LEUMUSIC_PROVIDERS"); v != "" {
+		cfg.Providers = splitList(v)
+	}
+	if v, ok := os.LookupEnv("LEUMUSIC_INCLUDE"); ok {
+		cfg.IncludeWords = splitList(v)
+	}
+	if v, ok := os.LookupEnv("LEUMUSIC_EXCLUDE"); ok {
+		words := splitList(v)
+		cfg.ExcludeWords = &words
+	}
+	if v := os.Getenv("LEUMUSIC_MIN_DURATION"); v != "" {
+		cfg.MinDuration = v
+	}
+	if v := os.Getenv("LEUMUSIC_MAX_DURATION"); v != "" {
+		cfg.MaxDuration = v
+	}
+	if v := os.Getenv("LEUMUSIC_TASK_TIMEOUT"); v != "" {
+		cfg.TaskTimeout = v
+	}
+	if v := os.Getenv("LEUMUSIC_RUN_TIMEOUT"); v != "" {
+		cfg.RunTimeout = v
+	}
+	if v := os.Getenv("LEUMUSIC_SYNC_REMOVED"); v != "" {
+		cfg.SyncRemoved = v
+	}
+	if v := os.Getenv("LEUMUSIC_COOKIE_SOURCE"); v != "" {
+		cfg.CookieSource = v
+	}
+	if v := os.Getenv("LEUMUSIC_YTDLP"); v != "" {
+		cfg.YtDlpPath = v
+	}
+	if v := os.Getenv("LEUMUSIC_FFMPEG"); v != "" {
+		cfg.FFmpegPath = v
+	}
+	if v := os.Getenv("LEUMUSIC_LANG"); v != "" {
+		cfg.Language = v
+	}
+
+	return errors.Join(errs...)
+}
+
+func applyConfig(cfg Config) {
+	useCookies = cfg.UseCookies
+	autoCookies = cfg.AutoCookies == nil || *cfg.AutoCookies
+	cookieSource = cfg.CookieSource
+	siteCookies = cfg.SiteCookies
+	turboMode = cfg.TurboMode
+	qualityMode = cfg.QualityMode
+	workersOverride = cfg.Workers
+	configuredLanguage = cfg.Language
+	matcher.Threshold = cfg.MatchThreshold
+	searchResults = cfg.SearchResults
+	batchSize = cfg.BatchSize
+	providers = cfg.Providers
+	retryPolicies = cfg.RetryPolicies
+	throttleConfig = cfg.Throttle
+	includeWords = cfg.IncludeWords
+	excludeWords = cfg.ExcludeWords
+	minDuration = cfg.MinDuration
+	maxDuration = cfg.MaxDuration
+	taskTimeout = cfg.TaskTimeout
+	runTimeout = cfg.RunTimeout
+	parkAfter = cfg.ParkAfter
+	syncRemoved = cfg.SyncRemoved
+	tools.SetPath("yt-dlp", cfg.YtDlpPath)
+	tools.SetPath("ffmpeg", cfg.FFmpegPath)
+}
+
+func currentConfig() Config {
+	var auto *bool
+	if !autoCookies {
+		auto = new(bool)
+	}
+	return Config{
+		AutoCookies:    auto,
+		CookieSource:   cookieSource,
+		SiteCookies:    siteCookies,
+		UseCookies:     useCookies,
+		TurboMode:      turboMode,
+		QualityMode:    qualityMode,
+		Workers:        workersOverride,
+		YtDlpPath:      tools.ConfiguredPath("yt-dlp"),
+		FFmpegPath:     tools.ConfiguredPath("ffmpeg"),
+		Language:       configuredLanguage,
+		MatchThreshold: matcher.Threshold,
+		SearchResults:  searchResults,
+		BatchSize:      batchSize,
+		Providers:      providers,
+		RetryPolicies:  retryPolicies,
+		Throttle:       throttleConfig,
+		IncludeWords:   includeWords,
+		ExcludeWords:   excludeWords,
+		MinDuration:    minDuration,
+		MaxDuration:    maxDuration,
+		TaskTimeout:    taskTimeout,
+		RunTimeout:     runTimeout,
+		ParkAfter:      parkAfter,
+		SyncRemoved:    syncRemoved,
+	}
+}
+
+// loadUserConfig reads only the user config file, without the project
+// file, environment or flags layered on top of it.
+func loadUserConfig() (Config, error) {
+	var cfg Config
+	path, err := userConfigPath()
+	if err != nil {
+		return cfg, err
+	}
+	return cfg, mergeConfigFile(&cfg, path)
+}
+
+func saveConfig(cfg Config) (string, error) {
+	path, err := userConfigPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return path, err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return path, err
+	}
+	return path, os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func retryPolicy(name string, rc RetryConfig) (leumusic.RetryPolicy, error) {
+	if _, ok := leumusic.ParseErrorKind(name); !ok {
+		return leumusic.RetryPolicy{}, fmt.Errorf("retry_policies: unknown error kind %q", name)
+	}
+	policy := leumusic.RetryPolicy{Retries: rc.Retries, GiveUp: rc.GiveUp}
+	var err error
+	if rc.Backoff != "" {
+		if policy.Backoff, err = leumusic.ParseDuration(rc.Backoff); err != nil {
+			return policy, fmt.Errorf("retry_policies.%s: %w", name, err)
+		}
+	}
+	if rc.MaxBackoff != "" {
+		if policy.MaxBackoff, err = leumusic.ParseDuration(rc.MaxBackoff); err != nil {
+			return policy, fmt.Errorf("retry_policies.%s: %w", name, err)
+		}
+	}
+	return policy, nil
+}
+
+// throttle applies tc on top of leumusic.DefaultThrottle.
+func throttle(tc *ThrottleConfig) (leumusic.Throttle, error) {
+	t := leumusic.DefaultThrottle()
+	if tc == nil {
+		return t, nil
+	}
+	if tc.Rate != 0 {
+		t.Rate = tc.Rate
+	}
+	if tc.Burst > 0 {
+		t.Burst = tc.Burst
+	}
+	switch {
+	case tc.BreakAfter < 0:
+		t.BreakAfter = 0
+	case tc.BreakAfter > 0:
+		t.BreakAfter = tc.BreakAfter
+	}
+	var err error
+	if tc.Jitter != "" {
+		if t.Jitter, err = leumusic.ParseDuration(tc.Jitter); err != nil {
+			return t, fmt.Errorf("throttle.jitter: %w", err)
+		}
+	}
+	if tc.Cooldown != "" {
+		if t.Cooldown, err = leumusic.ParseDuration(tc.Cooldown); err != nil {
+			return t, fmt.Errorf("throttle.cooldown: %w", err)
+		}
+	}
+	return t, nil
+}
+
+func validateProviders(names []string) error {
+	for _, name := range names {
+		if _, ok := leumusic.ParseProvider(name); !ok {
+			return fmt.Errorf("unknown provider %q (use youtube, youtube-music or soundcloud)", name)
+		}
+	}
+	return nil
+}
+
+func validateSyncRemoved(action string) error {
+	switch action {
+	case "", syncArchive, syncDelete:
+		return nil
+	}
+	return fmt.Errorf("sync_removed: unknown action %q (use archive or delete)", action)
+}
+
+func splitList(value string) []string {
+	words := []string{}
+	for _, word := range strings.Split(value, ",") {
+		if word = strings.TrimSpace(word); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func settingLabel[T comparable](value T, unset string) string {
+	var zero T
+	if value == zero {
+		return unset
+	}
+	return fmt.Sprint(value)
+}
+
+// stdin is shared by every prompt, so input typed ahead isn't lost to a
+// reader that is thrown away. Its lines are read once, in the background,
+// so downloads can listen for them too without stealing the next answer
+// from a prompt.
+var stdin = bufio.NewReader(os.Stdin)
+
+var stdinLines = sync.OnceValue(func() <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		for {
+			line, err := stdin.ReadString('\n')
+			if err != nil && line == "" {
+				return
+			}
+			lines <- strings.TrimSpace(line)
+		}
+	}()
+	return lines
+})
+
+// readLine returns the next line typed, and false at the end of input.
+func readLine() (string, bool) {
+	line, ok := <-stdinLines()
+	return line, ok
+}
+
+// interactive reports whether stdin is a terminal someone types in.
+func interactive() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func promptLine(prompt string) string {
+	fmt.Print(prompt)
+	line, _ := readLine()
+	return line
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/leuan/leumusic-downloader/leumusic"
+)
+
+// failedFile lists the songs that failed as a songs file, each with its
+// reason in a comment; failedReport and failedCSV hold the same list for
+// scripts. failedReport is also where the list is loaded from.
+const (
+	failedFile   = "failed.txt"
+	failedReport = "failed.json"
+	failedCSV    = "failed.csv"
+)
+
+var (
+	failures  *leumusic.Failures
+	parkAfter int
+)
+
+func loadFailures() {
+	var err error
+	failures, err = leumusic.OpenFailures(failedReport)
+	if err != nil {
+		log.Print(tr("failed.openError", failedReport, err))
+	}
+	if parkAfter != 0 {
+		failures.SetParkAfter(parkAfter)
+	}
+}
+
+// saveFailures writes the failed songs to failed.json, failed.txt and
+// failed.csv, or removes them once nothing has failed.
+func saveFailures() {
+	if err := failures.Save(); err != nil {
+		log.Print(tr("failed.writeError", failedReport, err))
+	}
+	if len(failures.Entries()) == 0 {
+		for _, path := range []string{failedFile, failedCSV} {
+			os.Remove(path)
+		}
+		return
+	}
+	if err := os.WriteFile(failedFile, []byte(failedText(failures.Entries())), 0644); err != nil {
+		log.Print(tr("failed.writeError", failedFile, err))
+	}
+	if err := writeFailedCSV(); err != nil {
+		log.Print(tr("failed.writeError", failedCSV, err))
+	}
+}
+
+func writeFailedCSV() error {
+	file, err := os.Create(failedCSV)
+	if err != nil {
+		return err
+	}
+	if err := failures.WriteCSV(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// failedText lists the songs in the songs file format, so failed.txt can
+// also be edited and downloaded with --file. Parked songs are commented
+// out.
+func failedText(entries []leumusic.Failure) string {
+	var b strings.Builder
+	b.WriteString(tr("failed.header"))
+	for _, parked := range []bool{false, true} {
+		for _, e := range entries {
+			if e.Parked != parked {
+				continue
+			}
+			b.WriteString("\n")
+			if parked {
+				b.WriteString(trn("failed.parkedEntry", e.Attempts, tr("reason."+e.Reason), e.Error, e.Attempts))
+			} else {
+				b.WriteString(trn("failed.entry", e.Attempts, tr("reason."+e.Reason), e.Error, e.Attempts))
+			}
+			b.WriteString("\n")
+			if parked {
+				b.WriteString("# ")
+			}
+			b.WriteString(e.Line())
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// retryFailed downloads the songs that failed before, skipping parked ones
+// unless withParked is set.
+func retryFailed(withParked bool) DownloadStats {
+	if withParked {
+		failures.Unpark()
+	}
+
+	var tasks []leumusic.DownloadTask
+	for _, task := range failures.Pending() {
+		if task.Song != "" && ledger.Has(task.Artist, task.Song) {
+			fmt.Println(tr("songs.alreadyDownloaded", task.Key()))
+			failures.Remove(task)
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	if len(tasks) == 0 {
+		saveFailures()
+		if parked := len(failures.Entries()); parked > 0 {
+			fmt.Println(trn("failed.onlyParked", parked, parked))
+		} else {
+			fmt.Println(tr("failed.none"))
+		}
+		return DownloadStats{}
+	}
+
+	fmt.Println(trn("failed.retrying", len(tasks), len(tasks)))
+	return processDownloads(tasks)
+}
+
+// printFailures lists the songs that failed in the last run and where they
+// were saved.
+func printFailures(failed []leumusic.Event) {
+	if len(failed) == 0 {
+		return
+	}
+	fmt.Println(tr("failed.title"))
+	for _, e := range failed {
+		fmt.Println(failureMessage(e.Task, e.Err))
+	}
+	fmt.Println(tr("failed.saved", failedFile, failedReport, failedCSV))
+}
@@ -1,3 +1,3 @@
-module main
+module github.com/leuan/leumusic-downloader
 
 go 1.24.4
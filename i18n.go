@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// catalog maps message keys to fmt format strings. Plural messages use the
+// suffixes ".one" and ".other" (and ".few"/".many" where a language needs
+// them); see pluralRules. Keys missing from a catalog fall back to English.
+type catalog map[string]string
+
+const fallbackLanguage = "en"
+
+var catalogs = map[string]catalog{
+	"en": catalogEN,
+	"es": catalogES,
+	"pt": catalogPT,
+	"it": catalogIT,
+}
+
+var pluralRules = map[string]func(n int) string{
+	"en": oneOther,
+	"es": oneOther,
+	"it": oneOther,
+	"pt": func(n int) string {
+		if n == 0 || n == 1 {
+			return "one"
+		}
+		return "other"
+	},
+}
+
+var currentLanguage = fallbackLanguage
+
+func oneOther(n int) string {
+	if n == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+func availableLanguages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// normalizeLanguage turns tags like "es_AR.UTF-8" or "pt-BR" into a
+// supported catalog name, or "" if the language is not available.
+func normalizeLanguage(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "_-.@"); i >= 0 {
+		tag = tag[:i]
+	}
+	if _, ok := catalogs[tag]; ok {
+		return tag
+	}
+	return ""
+}
+
+func setLanguage(tag string) bool {
+	lang := normalizeLanguage(tag)
+	if lang == "" {
+		return false
+	}
+	currentLanguage = lang
+	return true
+}
+
+// selectLanguage picks the first supported language among the --lang flag,
+// the configured language and the system locale.
+func selectLanguage(flagLang, configLang string) {
+	for _, tag := range []string{flagLang, configLang, systemLanguage()} {
+		if tag != "" && setLanguage(tag) {
+			return
+		}
+	}
+	currentLanguage = fallbackLanguage
+}
+
+func lookupMessage(key string) string {
+	if format, ok := catalogs[currentLanguage][key]; ok {
+		return format
+	}
+	if format, ok := catalogs[fallbackLanguage][key]; ok {
+		return format
+	}
+	return key
+}
+
+func tr(key string, args ...any) string {
+	format := lookupMessage(key)
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+func trn(key string, n int, args ...any) string {
+	rule, ok := pluralRules[currentLanguage]
+	if !ok {
+		rule = oneOther
+	}
+
+	pluralKey := key + "." + rule(n)
+	if _, ok := catalogs[currentLanguage][pluralKey]; !ok {
+		pluralKey = key + ".other"
+	}
+	return tr(pluralKey, args...)
+}
+
+func localeFromEnv() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" && v != "C" && v != "POSIX" {
+			return v
+		}
+	}
+	if v := os.Getenv("LANGUAGE"); v != "" {
+		return strings.SplitN(v, ":", 2)[0]
+	}
+	return ""
+}
@@ -0,0 +1,1078 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/leuan/leumusic-downloader/leumusic"
+)
+
+type DownloadStats struct {
+	success int32
+	failed  int32
+	skipped int32
+	paused  int32
+	// cancelled counts the songs cancelled by typing their number.
+	cancelled int32
+	// interrupted counts the songs left when the session was stopped, and
+	// timedOut says run_timeout stopped it.
+	interrupted int32
+	timedOut    bool
+}
+
+// errRunTimeout stops a run that takes longer than run_timeout.
+var errRunTimeout = errors.New("run_timeout reached")
+
+var (
+	useCookies         bool = false
+	autoCookies        bool = true
+	sessionCookies     bool
+	cookieSource       string
+	siteCookies        map[string]string
+	turboMode          bool = false
+	qualityMode        bool = false
+	recommendedWorkers int
+	configuredLanguage string
+	tools              = leumusic.NewToolbox(nil)
+	ledger             *leumusic.Store
+	journal            *leumusic.Journal
+	matcher            leumusic.Matcher
+	searchResults      int
+	batchSize          int
+	providers          []string
+	retryPolicies      map[string]RetryConfig
+	throttleConfig     *ThrottleConfig
+	includeWords       []string
+	excludeWords       *[]string
+	minDuration        string
+	maxDuration        string
+	taskTimeout        string
+	runTimeout         string
+)
+
+func toggleTurboMode() {
+	turboMode = !turboMode
+	if qualityMode {
+		qualityMode = false
+		fmt.Println(tr("turbo.qualityOff"))
+	}
+
+	if turboMode {
+		fmt.Println(tr("turbo.on", recommendedWorkers))
+		fmt.Println(tr("turbo.onHint"))
+	} else {
+		fmt.Println(tr("turbo.off"))
+	}
+}
+
+func toggleQualityMode() {
+	qualityMode = !qualityMode
+	if turboMode {
+		turboMode = false
+		fmt.Println(tr("quality.turboOff"))
+	}
+
+	if qualityMode {
+		fmt.Println(tr("quality.on", recommendedWorkers))
+		fmt.Println(tr("quality.onHint"))
+	} else {
+		fmt.Println(tr("quality.off"))
+	}
+}
+
+func saveModes(cfg *Config) {
+	cfg.TurboMode, cfg.QualityMode = turboMode, qualityMode
+}
+
+func toggleResetModes() {
+	turboMode = false
+	qualityMode = false
+	fmt.Println(tr("modes.reset"))
+}
+
+func songsFileName() string {
+	return tr("file.songs")
+}
+
+const ledgerFile = "leumusic-library.jsonl"
+
+// journalFile holds the songs of the running session until it completes,
+// so an interrupted one can be resumed.
+const journalFile = "leumusic-session.jsonl"
+
+// legacyLedgerFiles returns the downloaded.txt names used by older versions
+// in every language.
+func legacyLedgerFiles() []string {
+	seen := map[string]bool{}
+	var files []string
+	for _, lang := range availableLanguages() {
+		if name := catalogs[lang]["file.legacyLedger"]; name != "" && !seen[name] {
+			seen[name] = true
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+func main() {
+	langFlag, args := extractLangFlag(os.Args[1:])
+
+	cfg, err := loadConfig()
+	applyConfig(cfg)
+	selectLanguage(langFlag, configuredLanguage)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("config.problem", err))
+	}
+
+	loadDownloadedSongs()
+	loadSession()
+	loadFailures()
+	recommendedWorkers = leumusic.RecommendedWorkers()
+
+	if len(args) > 0 {
+		os.Exit(runCLI(args))
+	}
+
+	fmt.Println("🎵 LeuMusic Downloader - By Leuan")
+	fmt.Println("==========================================")
+	checkRequiredTools()
+	if pending := len(journal.Pending()); pending > 0 {
+		fmt.Println(trn("session.found", pending, pending))
+	}
+
+	for {
+		fmt.Println(tr("menu.options"))
+		fmt.Println("-------------------- LeuMusic --------------------")
+		fmt.Println(tr("menu.download", songsFileName()))
+		fmt.Println(tr("menu.manual"))
+		fmt.Println(tr("menu.cookies", useCookies))
+		fmt.Println(tr("menu.settings"))
+		fmt.Println()
+
+		fmt.Println("-------------------- By: Leuan --------------------")
+		fmt.Println(tr("menu.check"))
+		fmt.Println(tr("menu.tree"))
+		fmt.Println(tr("menu.list"))
+		if pending := len(journal.Pending()); pending > 0 {
+			fmt.Println(tr("menu.resume", pending))
+		}
+		if failed := len(failures.Pending()); failed > 0 {
+			fmt.Println(tr("menu.retry", failed))
+		}
+		if synced := len(leumusic.SyncedFolders(".")); synced > 0 {
+			fmt.Println(tr("menu.sync", synced))
+		}
+		fmt.Println(tr("menu.exit"))
+		fmt.Println()
+
+		fmt.Println(tr("menu.performance"))
+		fmt.Println(tr("menu.recommended", recommendedWorkers))
+		fmt.Println(tr("menu.turbo", turboMode))
+		fmt.Println(tr("menu.quality", qualityMode))
+		fmt.Println(tr("menu.reset"))
+
+		choice, _ := strconv.Atoi(promptLine(tr("menu.select")))
+
+		switch choice {
+		case 0, 9:
+			return
+		case 1:
+			downloadFromFile(songsFileName())
+		case 2:
+			manualInput()
+		case 3:
+			toggleCookies()
+			saveSettings(func(cfg *Config) { cfg.UseCookies = useCookies })
+		case 4:
+			settingsMenu()
+		case 5:
+			checkRequiredTools()
+		case 6:
+			showFolderStructure()
+		case 7:
+			showDownloadedSongs()
+		case 8:
+			resumeSession()
+		case 10:
+			toggleTurboMode()
+			saveSettings(saveModes)
+		case 11:
+			toggleQualityMode()
+			saveSettings(saveModes)
+		case 12:
+			toggleResetModes()
+			saveSettings(saveModes)
+		case 13:
+			retryFailed(false)
+		case 14:
+			syncPlaylists(nil, false)
+		default:
+			fmt.Println(tr("menu.invalid"))
+		}
+	}
+}
+
+func settingsMenu() {
+	for {
+		cfg := currentConfig()
+		fmt.Println(tr("settings.title"))
+		fmt.Println("--------------------")
+		fmt.Println(tr("settings.cookies", cfg.UseCookies))
+		fmt.Println(tr("settings.turbo", cfg.TurboMode))
+		fmt.Println(tr("settings.quality", cfg.QualityMode))
+		fmt.Println(tr("settings.workers", settingLabel(cfg.Workers, "auto")))
+		fmt.Println(tr("settings.ytdlp", settingLabel(cfg.YtDlpPath, "auto")))
+		fmt.Println(tr("settings.ffmpeg", settingLabel(cfg.FFmpegPath, "auto")))
+		fmt.Println(tr("settings.language", currentLanguage))
+		fmt.Println(tr("settings.match", settingLabel(cfg.MatchThreshold, "auto")))
+		fmt.Println(tr("settings.autoCookies", autoCookies))
+		fmt.Println(tr("settings.cookieSource", settingLabel(cookieSource, leumusic.DefaultCookieSource.String())))
+		fmt.Println(tr("settings.back"))
+		choice, _ := strconv.Atoi(promptLine(tr("menu.select")))
+
+		var set func(cfg *Config)
+		switch choice {
+		case 0:
+			return
+		case 1:
+			toggleCookies()
+			set = func(cfg *Config) { cfg.UseCookies = useCookies }
+		case 2:
+			toggleTurboMode()
+			set = saveModes
+		case 3:
+			toggleQualityMode()
+			set = saveModes
+		case 4:
+			workers, err := strconv.Atoi(promptLine(tr("settings.workersPrompt")))
+			if err != nil || workers < 0 {
+				fmt.Println(tr("settings.invalidNumber"))
+				continue
+			}
+			workersOverride = workers
+			set = func(cfg *Config) { cfg.Workers = workers }
+		case 5:
+			tools.SetPath("yt-dlp", promptLine(tr("settings.ytdlpPrompt")))
+			set = func(cfg *Config) { cfg.YtDlpPath = tools.ConfiguredPath("yt-dlp") }
+		case 6:
+			tools.SetPath("ffmpeg", promptLine(tr("settings.ffmpegPrompt")))
+			set = func(cfg *Config) { cfg.FFmpegPath = tools.ConfiguredPath("ffmpeg") }
+		case 7:
+			lang := promptLine(tr("settings.languagePrompt", strings.Join(availableLanguages(), ", ")))
+			if lang != "" && normalizeLanguage(lang) == "" {
+				fmt.Println(tr("settings.unknownLanguage", lang))
+				continue
+			}
+			configuredLanguage = normalizeLanguage(lang)
+			selectLanguage("", configuredLanguage)
+			set = func(cfg *Config) { cfg.Language = configuredLanguage }
+		case 8:
+			threshold, err := strconv.ParseFloat(promptLine(tr("settings.matchPrompt", leumusic.DefaultMatchThreshold)), 64)
+			if err != nil || threshold < 0 || threshold > 1 {
+				fmt.Println(tr("settings.invalidNumber"))
+				continue
+			}
+			matcher.Threshold = threshold
+			ledger.SetMatcher(matcher)
+			set = func(cfg *Config) { cfg.MatchThreshold = threshold }
+		case 9:
+			autoCookies = !autoCookies
+			auto := autoCookies
+			set = func(cfg *Config) { cfg.AutoCookies = &auto }
+		case 10:
+			spec := promptLine(tr("settings.cookieSourcePrompt", strings.Join(leumusic.CookieBrowsers, ", ")))
+			if _, err := leumusic.ParseCookieSource(spec); err != nil {
+				fmt.Println(tr("settings.invalidCookieSource", err))
+				continue
+			}
+			cookieSource = spec
+			set = func(cfg *Config) { cfg.CookieSource = spec }
+		default:
+			fmt.Println(tr("menu.invalid"))
+			continue
+		}
+
+		saveSettings(set)
+	}
+}
+
+// saveSettings applies a setting changed in the menu to the user config
+// file. Only that file is rewritten with it: values from ./leumusic.json,
+// the environment or flags stay where they came from.
+func saveSettings(set func(cfg *Config)) {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		fmt.Println(tr("settings.saveFailed", err))
+		return
+	}
+	set(&cfg)
+	path, err := saveConfig(cfg)
+	if err != nil {
+		fmt.Println(tr("settings.saveFailed", err))
+		return
+	}
+	fmt.Println(tr("settings.saved", path))
+}
+
+func toggleCookies() {
+	useCookies = !useCookies
+	fmt.Println(tr("cookies.state", useCookies))
+	if useCookies {
+		fmt.Println(tr("cookies.on"))
+	} else {
+		fmt.Println(tr("cookies.off"))
+	}
+}
+
+func checkRequiredTools() bool {
+	fmt.Println(tr("tools.checking"))
+
+	allOk := true
+	for _, tool := range leumusic.RequiredTools {
+		info, err := tools.Inspect(tool.Name, tool.VersionArg)
+		if err != nil {
+			fmt.Println(tr("tools.missing", tool.Name))
+			allOk = false
+		} else {
+			fmt.Printf("✅ %s: OK (%s) -> %s\n", info.Name, info.Version, info.Path)
+		}
+	}
+
+	if allOk {
+		checkCookies()
+	}
+
+	if !allOk {
+		fmt.Println(tr("tools.install"))
+		for _, hint := range leumusic.InstallHints() {
+			fmt.Println(hint)
+		}
+		fmt.Println(tr("tools.placeHint"))
+	}
+
+	return allOk
+}
+
+// checkCookies reads the cookie sources the way a download would. An
+// unreadable source is only a warning, since cookies are optional.
+func checkCookies() {
+	src, sites := currentCookies()
+	check := func(src leumusic.CookieSource, site string) {
+		if src == (leumusic.CookieSource{}) {
+			src = leumusic.DefaultCookieSource
+		}
+		if src.Disabled {
+			return
+		}
+		n, err := leumusic.CheckCookies(tools, src, site)
+		switch {
+		case err != nil:
+			fmt.Println(tr("tools.cookiesError", src, err))
+		case n == 0:
+			fmt.Println(tr("tools.cookiesEmpty", src, site))
+		default:
+			fmt.Println(trn("tools.cookies", n, src, n, site))
+		}
+	}
+
+	check(src, "youtube.com")
+	for _, site := range slices.Sorted(maps.Keys(sites)) {
+		check(sites[site], site)
+	}
+}
+
+func loadDownloadedSongs() {
+	var err error
+	ledger, err = leumusic.OpenStore(ledgerFile)
+	if err != nil {
+		log.Print(tr("ledger.openError", ledgerFile, err))
+	}
+	ledger.SetMatcher(matcher)
+
+	for _, legacy := range legacyLedgerFiles() {
+		if _, err := os.Stat(legacy); err != nil {
+			continue
+		}
+		imported, err := ledger.ImportText(legacy)
+		if err != nil {
+			log.Print(tr("ledger.openError", legacy, err))
+			continue
+		}
+		backup := legacy + ".imported"
+		if err := os.Rename(legacy, backup); err != nil {
+			log.Print(tr("ledger.writeError", legacy, err))
+			continue
+		}
+		fmt.Println(trn("ledger.imported", imported, imported, legacy, backup))
+	}
+}
+
+func loadSession() {
+	var err error
+	journal, err = leumusic.OpenJournal(journalFile)
+	if err != nil {
+		log.Print(tr("session.openError", journalFile, err))
+	}
+}
+
+// resumeSession downloads the songs an interrupted session didn't get to.
+func resumeSession() DownloadStats {
+	tasks := journal.Pending()
+	if len(tasks) == 0 {
+		fmt.Println(tr("session.none"))
+		return DownloadStats{}
+	}
+	fmt.Println(trn("session.resuming", len(tasks), len(tasks)))
+	return processDownloads(tasks)
+}
+
+func downloadFromFile(path string) (DownloadStats, error) {
+	if leumusic.IsImportFile(path) {
+		return downloadImported(path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Println(tr("file.notFound", path))
+		if path == songsFileName() {
+			fmt.Println(tr("file.creatingExample"))
+			createExampleFile()
+		}
+		return DownloadStats{}, err
+	}
+	defer file.Close()
+
+	var tasks []leumusic.DownloadTask
+	scanner := bufio.NewScanner(file)
+
+	lineCount := 0
+	validCount := 0
+	skippedCount := 0
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		lineCount++
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		task, err := leumusic.ParseTask(scanner.Text())
+		if err == nil {
+			if !queueNew(&tasks, *task) {
+				skippedCount++
+				continue
+			}
+			validCount++
+		} else {
+			fmt.Println(lineProblem(lineCount, scanner.Text(), err))
+		}
+	}
+
+	if len(tasks) == 0 {
+		fmt.Println(tr("songs.noneNew", skippedCount))
+		return DownloadStats{}, nil
+	}
+
+	fmt.Println(trn("songs.found", validCount, validCount, skippedCount))
+	return processDownloads(tasks), nil
+}
+
+// downloadImported downloads the new songs of a song list exported from
+// another app, such as Spotify or iTunes, and then writes the .m3u8 of
+// each playlist in it.
+func downloadImported(path string) (DownloadStats, error) {
+	imported, err := leumusic.ImportFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Println(tr("file.notFound", path))
+		} else {
+			fmt.Println(tr("import.readError", err))
+		}
+		return DownloadStats{}, err
+	}
+	fmt.Println(trn("import.read", len(imported), len(imported), path))
+
+	var tasks []leumusic.DownloadTask
+	for _, task := range imported {
+		queueNew(&tasks, task)
+	}
+	skipped := len(imported) - len(tasks)
+	var stats DownloadStats
+	if len(tasks) == 0 {
+		fmt.Println(tr("songs.noneNew", skipped))
+	} else {
+		fmt.Println(trn("songs.found", len(tasks), len(tasks), skipped))
+		stats = processDownloads(tasks)
+	}
+	for _, p := range importedPlaylists(imported) {
+		writePlaylistFile(p)
+	}
+	return stats, nil
+}
+
+// queueNew adds task to tasks unless it was downloaded, is parked or is
+// queued already, saying which, and reports whether it was added. A song
+// queued for another playlist is downloaded once; the caller keeps every
+// playlist it is in.
+func queueNew(tasks *[]leumusic.DownloadTask, task leumusic.DownloadTask) bool {
+	if task.Song != "" && ledger.Has(task.Artist, task.Song) {
+		fmt.Println(tr("songs.alreadyDownloaded", task.Key()))
+		return false
+	}
+	if f, ok := failures.Get(task); ok && f.Parked {
+		fmt.Println(trn("songs.parked", f.Attempts, task.Key(), f.Attempts))
+		return false
+	}
+	if dup, ok := findQueued(*tasks, task); ok {
+		if task.Playlist != "" && task.Playlist != dup.Playlist {
+			fmt.Println(tr("songs.otherPlaylist", task.Playlist, task.Key()))
+		} else {
+			fmt.Println(tr("songs.duplicate", task.Key(), dup.Key()))
+		}
+		return false
+	}
+	*tasks = append(*tasks, task)
+	return true
+}
+
+// lineProblem explains why a songs file line can't be read, with a caret
+// under the column where the problem is.
+func lineProblem(number int, line string, err error) string {
+	var le *leumusic.LineError
+	if !errors.As(err, &le) {
+		return tr("songs.invalidLine", number, 1, err, line, "^")
+	}
+	var caret strings.Builder
+	for i, r := range []rune(line) {
+		if i >= le.Column-1 {
+			break
+		}
+		if r == '\t' {
+			caret.WriteRune('\t')
+		} else {
+			caret.WriteRune(' ')
+		}
+	}
+	caret.WriteRune('^')
+	return tr("songs.invalidLine", number, le.Column, le.Message, line, caret.String())
+}
+
+// findQueued returns the queued task that is the same song as task, or
+// the same URL for tasks without a song.
+func findQueued(tasks []leumusic.DownloadTask, task leumusic.DownloadTask) (leumusic.DownloadTask, bool) {
+	for _, queued := range tasks {
+		if task.Song == "" || queued.Song == "" {
+			if queued.Song == task.Song && queued.URL == task.URL {
+				return queued, true
+			}
+			continue
+		}
+		if _, ok := matcher.Same(queued, task); ok {
+			return queued, true
+		}
+	}
+	return leumusic.DownloadTask{}, false
+}
+
+func queueSong(line string, queued []leumusic.DownloadTask) (leumusic.DownloadTask, bool) {
+	task, err := leumusic.ParseTask(line)
+	var le *leumusic.LineError
+	if errors.As(err, &le) {
+		fmt.Println(tr("manual.invalid", le.Column, le.Message))
+		return leumusic.DownloadTask{}, false
+	}
+
+	if task.Song != "" && ledger.Has(task.Artist, task.Song) {
+		fmt.Println(tr("songs.alreadyDownloaded", task.Key()))
+		return leumusic.DownloadTask{}, false
+	}
+	if dup, ok := findQueued(queued, *task); ok {
+		fmt.Println(tr("songs.duplicate", task.Key(), dup.Key()))
+		return leumusic.DownloadTask{}, false
+	}
+
+	fmt.Println(tr("manual.added", task.Key()))
+	return *task, true
+}
+
+func addSongs(lines []string) DownloadStats {
+	var tasks []leumusic.DownloadTask
+	var invalid int32
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if leumusic.ParseLine(line) == nil {
+			invalid++
+		}
+		if task, ok := queueSong(line, tasks); ok {
+			tasks = append(tasks, task)
+		}
+	}
+
+	var stats DownloadStats
+	if len(tasks) > 0 {
+		stats = processDownloads(tasks)
+	}
+	stats.failed += invalid
+	return stats
+}
+
+func manualInput() DownloadStats {
+	finishWord := tr("manual.finishWord")
+	fmt.Println(tr("manual.intro"))
+	fmt.Println(tr("manual.example"))
+	fmt.Println(tr("manual.finish", finishWord))
+
+	var tasks []leumusic.DownloadTask
+
+	for {
+		fmt.Print("> ")
+		line, ok := readLine()
+		if !ok {
+			break
+		}
+
+		if word := strings.ToLower(line); word == finishWord || word == "fin" {
+			break
+		}
+
+		if line == "" {
+			continue
+		}
+
+		if task, ok := queueSong(line, tasks); ok {
+			tasks = append(tasks, task)
+		}
+	}
+
+	if len(tasks) == 0 {
+		return DownloadStats{}
+	}
+	return processDownloads(tasks)
+}
+
+func currentQuality() leumusic.Quality {
+	switch {
+	case turboMode:
+		return leumusic.QualityTurbo
+	case qualityMode:
+		return leumusic.QualityHigh
+	default:
+		return leumusic.QualityNormal
+	}
+}
+
+// currentFilter builds the search filter from the configured words and
+// durations, falling back to the defaults for anything unset.
+func currentFilter() leumusic.Filter {
+	filter := leumusic.DefaultFilter()
+	filter.Include = includeWords
+	if excludeWords != nil {
+		filter.Exclude = *excludeWords
+	}
+	if d, err := leumusic.ParseDuration(minDuration); minDuration != "" && err == nil {
+		filter.MinDuration = d
+	}
+	if d, err := leumusic.ParseDuration(maxDuration); maxDuration != "" && err == nil {
+		filter.MaxDuration = d
+	}
+	return filter
+}
+
+// currentCookies returns the configured cookie source and the per-site
+// overrides. Invalid entries were already reported by loadConfig.
+func currentCookies() (leumusic.CookieSource, map[string]leumusic.CookieSource) {
+	src, _ := leumusic.ParseCookieSource(cookieSource)
+	sites := make(map[string]leumusic.CookieSource, len(siteCookies))
+	for site, spec := range siteCookies {
+		if s, err := leumusic.ParseCookieSource(spec); err == nil {
+			sites[site] = s
+		}
+	}
+	return src, sites
+}
+
+func currentProviders() []leumusic.Provider {
+	var list []leumusic.Provider
+	for _, name := range providers {
+		if p, ok := leumusic.ParseProvider(name); ok {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// currentRetryPolicies applies the configured overrides on top of the
+// library defaults.
+func currentRetryPolicies() map[leumusic.ErrorKind]leumusic.RetryPolicy {
+	policies := maps.Clone(leumusic.DefaultRetryPolicies)
+	for name, rc := range retryPolicies {
+		kind, _ := leumusic.ParseErrorKind(name)
+		if policy, err := retryPolicy(name, rc); err == nil {
+			policies[kind] = policy
+		}
+	}
+	return policies
+}
+
+// reasonLabel names the category of a download error in the current
+// language.
+func reasonLabel(err error) string {
+	return tr("reason." + leumusic.ErrorKindOf(err).String())
+}
+
+// currentTaskTimeout turns the configured task timeout into
+// Options.TaskTimeout: unset uses the library default, 0 means no limit.
+func currentTaskTimeout() time.Duration {
+	d, err := leumusic.ParseDuration(taskTimeout)
+	switch {
+	case taskTimeout == "" || err != nil:
+		return 0
+	case d == 0:
+		return -1
+	}
+	return d
+}
+
+func failureMessage(task leumusic.DownloadTask, err error) string {
+	var de *leumusic.DownloadError
+	if errors.As(err, &de) {
+		return tr("download.failedTask", task.Key(), reasonLabel(err), de.Message)
+	}
+	return tr("download.error", err)
+}
+
+// newDownloader returns a downloader with the current settings that
+// reports to onEvent.
+func newDownloader(onEvent func(leumusic.Event)) *leumusic.Downloader {
+	return leumusic.New(downloaderOptions(onEvent))
+}
+
+// downloaderOptions gathers the current settings into the options every
+// downloader is built with.
+func downloaderOptions(onEvent func(leumusic.Event)) leumusic.Options {
+	filter := currentFilter()
+	cookies, sites := currentCookies()
+	throttle, _ := throttle(throttleConfig)
+	return leumusic.Options{
+		Workers:         workersOverride,
+		Quality:         currentQuality(),
+		UseCookies:      useCookies || sessionCookies,
+		EscalateCookies: autoCookies,
+		Cookies:         cookies,
+		SiteCookies:     sites,
+		Throttle:        &throttle,
+		Tools:           tools,
+		Ledger:          ledger,
+		Match:           matcher,
+		SearchResults:   searchResults,
+		BatchSize:       batchSize,
+		TaskTimeout:     currentTaskTimeout(),
+		Filter:          &filter,
+		Providers:       currentProviders(),
+		RetryPolicies:   currentRetryPolicies(),
+		UnnamedFolder:   tr("folder.unnamed"),
+		OnEvent:         onEvent,
+	}
+}
+
+func processDownloads(tasks []leumusic.DownloadTask) DownloadStats {
+	startTime := time.Now()
+
+	var stats DownloadStats
+	searchLog, closeSearchLog := openSearchLog()
+	defer closeSearchLog()
+	record := func(index int, state leumusic.TaskState, err error) {
+		if err := journal.Set(index, state, err); err != nil {
+			log.Print(tr("session.writeError", journalFile, err))
+		}
+	}
+	var failedMu sync.Mutex
+	var failed []leumusic.Event
+	var parked int
+
+	// Ctrl+C or SIGTERM stops the downloads and kills yt-dlp and ffmpeg;
+	// a second Ctrl+C quits right away. run_timeout stops them the same way.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if d, err := leumusic.ParseDuration(runTimeout); err == nil && d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, d, errRunTimeout)
+		defer cancel()
+	}
+
+	// Typing a song's number and Enter cancels it, when someone is there
+	// to type.
+	listening := interactive()
+	downloader := newDownloader(func(e leumusic.Event) {
+		switch e.Kind {
+		case leumusic.EventStarted:
+			if listening {
+				fmt.Println(tr("download.started", e.Index+1, e.Task.Key()))
+			}
+			record(e.Index, leumusic.TaskRunning, nil)
+		case leumusic.EventSkipped:
+			fmt.Println(tr("download.existsOnDisk", e.Task.Key()))
+			atomic.AddInt32(&stats.skipped, 1)
+			atomic.AddInt32(&stats.success, 1)
+			record(e.Index, leumusic.TaskDone, nil)
+			failures.Remove(e.Task)
+		case leumusic.EventSucceeded:
+			atomic.AddInt32(&stats.success, 1)
+			record(e.Index, leumusic.TaskDone, nil)
+			failures.Remove(e.Task)
+		case leumusic.EventFailed:
+			if ctx.Err() != nil && errors.Is(e.Err, context.Cause(ctx)) {
+				// Stopped, not failed: it stays queued for resuming.
+				atomic.AddInt32(&stats.interrupted, 1)
+				record(e.Index, leumusic.TaskQueued, nil)
+				break
+			}
+			if errors.Is(e.Err, leumusic.ErrTaskCancelled) {
+				// Left out on purpose: not a failure to retry or resume.
+				fmt.Println(tr("download.cancelled", e.Task.Key()))
+				atomic.AddInt32(&stats.cancelled, 1)
+				record(e.Index, leumusic.TaskFailed, e.Err)
+				break
+			}
+			fmt.Println(failureMessage(e.Task, e.Err))
+			atomic.AddInt32(&stats.failed, 1)
+			record(e.Index, leumusic.TaskFailed, e.Err)
+			entry := failures.Add(e.Task, e.Err)
+			failedMu.Lock()
+			failed = append(failed, e)
+			if entry.Parked {
+				parked++
+			}
+			failedMu.Unlock()
+		case leumusic.EventRetrying:
+			fmt.Println(tr("download.retrying", e.Task.Key(), reasonLabel(e.Err), e.Wait, e.Attempt+1))
+		case leumusic.EventCookiesEscalated:
+			fmt.Println(tr("download.cookiesEscalated", e.Task.Key(), reasonLabel(e.Err)))
+		case leumusic.EventCookiesSticky:
+			fmt.Println(tr("download.cookiesSticky"))
+		case leumusic.EventPaused:
+			fmt.Println(tr("download.paused", e.Attempt, reasonLabel(e.Err), e.Wait))
+			atomic.AddInt32(&stats.paused, 1)
+		case leumusic.EventResumed:
+			fmt.Println(tr("download.resumed", e.Wait))
+		case leumusic.EventLedgerError:
+			log.Print(tr("ledger.writeError", ledgerFile, e.Err))
+		case leumusic.EventResolved:
+			logSearchDecision(searchLog, e)
+		case leumusic.EventFallback:
+			logFallback(searchLog, e)
+		}
+	})
+
+	tasks, playlists := expandPlaylists(ctx, downloader, tasks, &stats)
+	if len(tasks) == 0 {
+		saveFailures()
+		return stats
+	}
+	fmt.Println(trn("download.starting", len(tasks), len(tasks)))
+	if err := journal.Begin(tasks); err != nil {
+		log.Print(tr("session.writeError", journalFile, err))
+	}
+	if workersOverride > 0 {
+		fmt.Println(tr("download.workers", downloader.Workers()))
+	} else {
+		fmt.Println(tr("download.workersAdaptive", downloader.Workers(), leumusic.MaxWorkers()))
+	}
+
+	done := make(chan bool)
+	go func() {
+		ticker := time.NewTicker(2 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				success := atomic.LoadInt32(&stats.success)
+				failed := atomic.LoadInt32(&stats.failed)
+				completed := success + failed + atomic.LoadInt32(&stats.cancelled)
+				progress := float64(completed) / float64(len(tasks)) * 100
+				stages := downloader.Stages()
+				fmt.Print(tr("download.progress", progress, completed, len(tasks), success, failed, downloader.Workers(),
+					stages[leumusic.StageResolve].Active, stages[leumusic.StageResolve].Queued,
+					stages[leumusic.StageFetch].Active, stages[leumusic.StageFetch].Queued,
+					stages[leumusic.StageTranscode].Active, stages[leumusic.StageTranscode].Queued))
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	if listening {
+		fmt.Println(tr("download.cancelHint"))
+		go cancelTyped(downloader, len(tasks), done)
+	}
+
+	stopping := context.AfterFunc(ctx, func() {
+		stop()
+		if errors.Is(context.Cause(ctx), errRunTimeout) {
+			fmt.Println(tr("download.runTimeout", runTimeout))
+		} else {
+			fmt.Println(tr("download.stopping"))
+		}
+	})
+	downloader.Download(ctx, tasks)
+	stopping()
+	stats.timedOut = errors.Is(context.Cause(ctx), errRunTimeout)
+	close(done)
+	for _, p := range playlists {
+		writePlaylistFile(p)
+	}
+	if downloader.StickyCookies() {
+		// Keep using cookies for the rest of the session, without saving it.
+		sessionCookies = true
+	}
+
+	duration := time.Since(startTime)
+
+	fmt.Println(tr("download.completed", duration.Round(time.Second)))
+	fmt.Println(tr("download.success", stats.success))
+	fmt.Println(tr("download.failed", stats.failed))
+	if stats.cancelled > 0 {
+		fmt.Println(trn("download.cancels", int(stats.cancelled), stats.cancelled))
+	}
+	saveFailures()
+	printFailures(failed)
+	if parked > 0 {
+		fmt.Println(trn("failed.parked", parked, parked))
+	}
+	if stats.paused > 0 {
+		fmt.Println(trn("download.pauses", int(stats.paused), stats.paused))
+	}
+	if stats.interrupted > 0 {
+		fmt.Println(trn("session.interrupted", int(stats.interrupted), stats.interrupted))
+	} else if err := journal.Clear(); err != nil {
+		log.Print(tr("session.writeError", journalFile, err))
+	}
+
+	if stats.success > 0 {
+		fmt.Println(tr("download.registered", ledgerFile))
+		fmt.Println(tr("download.structure"))
+		showFolderStructure()
+	}
+
+	return stats
+}
+
+// cancelTyped cancels the songs whose numbers are typed while n songs
+// download, until done is closed.
+func cancelTyped(downloader *leumusic.Downloader, n int, done <-chan bool) {
+	for {
+		select {
+		case line, ok := <-stdinLines():
+			if !ok {
+				return
+			}
+			if line == "" {
+				continue
+			}
+			number, err := strconv.Atoi(strings.TrimPrefix(line, "#"))
+			if err != nil || number < 1 || number > n || !downloader.Cancel(number-1) {
+				fmt.Println(tr("download.cancelUnknown", line))
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+func showDownloadedSongs() {
+	records := ledger.Records()
+	if len(records) == 0 {
+		fmt.Println(tr("list.empty"))
+		return
+	}
+
+	fmt.Println(tr("list.title"))
+	fmt.Println("==========================")
+
+	for _, rec := range records {
+		if rec.VideoID != "" {
+			fmt.Printf("✅ %s  [%s, %s]\n", rec.Key(), rec.VideoID, rec.Quality)
+		} else {
+			fmt.Printf("✅ %s\n", rec.Key())
+		}
+	}
+
+	fmt.Println(trn("list.total", len(records), len(records)))
+}
+
+func showFolderStructure() {
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		fmt.Println(tr("tree.readError", err))
+		return
+	}
+
+	foundFolders := false
+	totalSongs := 0
+
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			foundFolders = true
+			mp3Files, _ := filepath.Glob(filepath.Join(entry.Name(), "*.mp3"))
+			flacFiles, _ := filepath.Glob(filepath.Join(entry.Name(), "*.flac"))
+			mp3Files = append(mp3Files, flacFiles...)
+			fmt.Println(trn("tree.folder", len(mp3Files), entry.Name(), len(mp3Files)))
+			totalSongs += len(mp3Files)
+
+			for i, file := range mp3Files {
+				if i < 2 {
+					fmt.Printf("   🎵 %s\n", filepath.Base(file))
+				} else if i == 2 && len(mp3Files) > 3 {
+					fmt.Println(tr("tree.more", len(mp3Files)-2))
+					break
+				}
+			}
+		}
+	}
+
+	if foundFolders {
+		fmt.Println(tr("tree.total", totalSongs))
+	} else {
+		fmt.Println(tr("tree.empty"))
+	}
+}
+
+func createExampleFile() {
+	exampleContent := tr("example.header") + `
+pearl jam - even flow
+justin bieber - baby
+queen - bohemian rhapsody
+metallica - enter sandman
+adele - rolling in the deep
+coldplay - yellow
+ed sheeran - shape of you
+taylor swift - shake it off
+nirvana - smells like teen spirit
+oasis - wonderwall`
+
+	err := os.WriteFile(songsFileName(), []byte(exampleContent), 0644)
+	if err != nil {
+		fmt.Println(tr("example.error", err))
+		return
+	}
+
+	fmt.Println(tr("example.created", songsFileName()))
+}
@@ -0,0 +1,154 @@
+package leumusic
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// workerMemory is roughly what one yt-dlp and ffmpeg pair needs.
+const workerMemory = 256 << 20
+
+// maxWorkersCap keeps the pool small enough not to look like a crawler,
+// however big the machine is.
+const maxWorkersCap = 16
+
+// DefaultAdjustEvery is how often an adaptive pool is resized when
+// Options.AdjustEvery is zero.
+const DefaultAdjustEvery = 15 * time.Second
+
+// SystemCPUs returns how many CPUs this process may use, taking a cgroup
+// CPU quota into account.
+func SystemCPUs() int {
+	cpus := runtime.NumCPU()
+	if quota := cgroupCPUs(); quota > 0 && int(math.Ceil(quota)) < cpus {
+		cpus = int(math.Ceil(quota))
+	}
+	return cpus
+}
+
+// MaxWorkers returns the most workers this machine can run at once: four
+// per CPU, as long as they fit in half of the memory.
+func MaxWorkers() int {
+	workers := SystemCPUs() * 4
+	if memory := SystemMemory(); memory > 0 {
+		workers = min(workers, int(memory/2/workerMemory))
+	}
+	return max(1, min(workers, maxWorkersCap))
+}
+
+// pool hands out worker slots up to a limit that can change while tasks
+// are running. Lowering it doesn't stop running tasks; it only holds new
+// ones back until enough have finished.
+type pool struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	limit  int
+	active int
+}
+
+func newPool(limit int) *pool {
+	p := &pool{limit: limit}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// acquire waits for a free slot. It returns false if ctx is done first.
+func (p *pool) acquire(ctx context.Context) bool {
+	stop := context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.cond.Broadcast()
+	})
+	defer stop()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.active >= p.limit && ctx.Err() == nil {
+		p.cond.Wait()
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	p.active++
+	return true
+}
+
+func (p *pool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active--
+	p.cond.Broadcast()
+}
+
+func (p *pool) setLimit(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.limit = n
+	p.cond.Broadcast()
+}
+
+func (p *pool) busy() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.active >= p.limit
+}
+
+// adapt resizes the pool every AdjustEvery until stop is closed, AIMD
+// style: one more worker while the pool is full and throughput keeps up,
+// half as many when errors pile up or the machine runs out of CPU or
+// memory.
+func (d *Downloader) adapt(p *pool, stop <-chan struct{}) {
+	ticker := time.NewTicker(d.opts.AdjustEvery)
+	defer ticker.Stop()
+
+	lastDone, lastRuns, lastFailed := d.finished.Load(), d.runs.Load(), d.failedRuns.Load()
+	lastBusy, lastTotal, _ := cpuTimes()
+	var lastRate float64
+	grew := false
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		done, runs, failed := d.finished.Load(), d.runs.Load(), d.failedRuns.Load()
+		rate := float64(done - lastDone)
+		errorRate := 0.0
+		if runs > lastRuns {
+			errorRate = float64(failed-lastFailed) / float64(runs-lastRuns)
+		}
+		cpu := 0.0
+		if busy, total, ok := cpuTimes(); ok && total > lastTotal {
+			cpu = float64(busy-lastBusy) / float64(total-lastTotal)
+			lastBusy, lastTotal = busy, total
+		}
+		free := availableMemory()
+
+		limit := int(d.workers.Load())
+		next := limit
+		switch {
+		case errorRate >= 0.25 || cpu >= 0.9 || (free > 0 && free < 2*workerMemory):
+			next = limit / 2
+		case grew && rate < lastRate*0.9:
+			// The last worker added made things slower.
+			next = limit - 1
+		case p.busy() && free != 0 && free < 4*workerMemory:
+			// Full, but another worker wouldn't fit in memory.
+		case p.busy():
+			next = limit + 1
+		}
+		next = max(1, min(next, d.opts.MaxWorkers))
+
+		grew = next > limit
+		if next != limit {
+			d.workers.Store(int32(next))
+			p.setLimit(next)
+		}
+		lastDone, lastRuns, lastFailed, lastRate = done, runs, failed, rate
+	}
+}
@@ -0,0 +1,252 @@
+package leumusic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultBatchWait is how long the fetch stage waits to fill a batch when
+// Options.BatchWait is zero.
+const DefaultBatchWait = 500 * time.Millisecond
+
+// drainBatches is the fetch stage when Options.BatchSize is above 1. It
+// gathers up to BatchSize jobs, or whatever arrived within BatchWait, and
+// fetches them with a single yt-dlp process to save its startup time.
+func (d *Downloader) drainBatches(ctx context.Context, p *pool, in <-chan *job, out chan<- *job, finish func(*job, error)) {
+	var wg sync.WaitGroup
+	for j := range in {
+		batch := []*job{j}
+		timer := time.NewTimer(d.opts.BatchWait)
+	gather:
+		for len(batch) < d.opts.BatchSize {
+			select {
+			case j, ok := <-in:
+				if !ok {
+					break gather
+				}
+				batch = append(batch, j)
+			case <-timer.C:
+				break gather
+			}
+		}
+		timer.Stop()
+
+		p.acquire(context.WithoutCancel(ctx))
+		wg.Add(1)
+		go func(batch []*job) {
+			defer wg.Done()
+			defer p.release()
+			d.fetchBatch(batch, out, finish)
+		}(batch)
+	}
+	wg.Wait()
+}
+
+// fetchBatch fetches the next candidate of every job in one go, grouped
+// by the cookies they need. Jobs that fail are carried on one by one by
+// fetch, which retries and falls back as usual.
+func (d *Downloader) fetchBatch(batch []*job, out chan<- *job, finish func(*job, error)) {
+	for range batch {
+		d.stages[StageFetch].start()
+	}
+	groups, single := d.groupBatch(batch)
+
+	errs := map[*job]error{}
+	for _, group := range groups {
+		for j, err := range d.fetchGroup(group) {
+			errs[j] = err
+		}
+	}
+	for _, j := range single {
+		errs[j] = errors.New("not batched")
+	}
+
+	for _, j := range batch {
+		err := errs[j]
+		if j.ctx.Err() != nil {
+			err = context.Cause(j.ctx)
+			j.discardFetched()
+		}
+		settled := err == nil || d.fatal(err) || (j.task.URL != "" && d.finalInBatch(err))
+		if !settled {
+			_, err = d.fetch(j)
+		}
+		d.leaveStage(StageFetch, j, out, false, err, finish)
+	}
+}
+
+// groupBatch splits the jobs of a batch into those fetched together,
+// grouped by the cookies they need, and those left to fetch on their own.
+func (d *Downloader) groupBatch(batch []*job) (groups map[string][]*job, single []*job) {
+	groups = map[string][]*job{}
+	videos := map[string]bool{}
+	for _, j := range batch {
+		// First-hit searches can't be told apart in yt-dlp's output.
+		if j.matchFilter != "" || j.next >= len(j.candidates) {
+			single = append(single, j)
+			continue
+		}
+		// yt-dlp fetches a video once however often it's asked for, and
+		// two jobs sharing its file would remove it from under each other.
+		c := j.candidates[j.next]
+		video := c.ID
+		if video == "" {
+			video = c.URL
+		}
+		if videos[video] {
+			single = append(single, j)
+			continue
+		}
+		videos[video] = true
+
+		key := strings.Join(d.batchCookies(j), "\x00")
+		groups[key] = append(groups[key], j)
+	}
+	return groups, single
+}
+
+func (d *Downloader) batchCookies(j *job) []string {
+	if !j.cookies {
+		return nil
+	}
+	return d.cookieSource(j.candidates[j.next].URL).args()
+}
+
+// fetchGroup runs one yt-dlp for the next candidate of every job and maps
+// its output back to them. A job that succeeded moves past the candidate,
+// as does one that failed in a way no retry would fix. The others keep it,
+// with the error the batch got for it.
+func (d *Downloader) fetchGroup(group []*job) map[*job]error {
+	errs := make(map[*job]error, len(group))
+	// One folder per video, each removed after transcoding like a single
+	// fetch's. They are named after one reserved for this batch, so
+	// batches running side by side never fetch into the same folder.
+	reserved, err := d.workFolder()
+	if err != nil {
+		for _, j := range group {
+			errs[j] = err
+		}
+		return errs
+	}
+	defer os.Remove(reserved)
+	args := fetchArgs(filepath.Join(reserved+"-%(id)s", "%(title)s"+sourceSuffix+".%(ext)s"))
+	args = append(args, d.batchCookies(group[0])...)
+	for _, j := range group {
+		args = append(args, j.candidates[j.next].URL)
+	}
+
+	ctx, cancel := groupContext(group)
+	defer cancel()
+	output, runErr := d.runYtDlp(ctx, args...)
+	if errors.Is(runErr, ErrToolMissing) {
+		for _, j := range group {
+			errs[j] = runErr
+		}
+		return errs
+	}
+
+	infos := parseBatchInfo(string(output))
+	for _, j := range group {
+		c := j.candidates[j.next]
+		info, ok := infos[c.URL]
+		if !ok && c.ID != "" {
+			info, ok = infos[c.ID]
+		}
+
+		var err error
+		if ok {
+			info.Provider, info.Rank = c.Provider, c.Rank
+			j.info, j.raw, j.thumbnail = info, info.Filepath, findThumbnail(info.Filepath)
+			j.next++
+			j.attempts++
+		} else {
+			err = batchError(string(output), c, runErr)
+		}
+		d.runs.Add(1)
+		if streak := d.limiter.report(err); streak > 0 {
+			d.emit(Event{Kind: EventPaused, Index: j.index, Task: j.task, Err: err, Attempt: streak, Wait: d.opts.Throttle.Cooldown})
+		}
+		if err != nil && d.finalInBatch(err) && j.task.URL == "" {
+			j.lastErr = err
+			j.next++
+			j.attempts++
+			d.emit(Event{Kind: EventFallback, Index: j.index, Task: j.task, Candidates: j.all[j.next-1:], Err: err})
+		}
+		errs[j] = err
+	}
+	return errs
+}
+
+// groupContext is done once every job of the group is, so one song timing
+// out or being cancelled doesn't stop the others' batch.
+func groupContext(group []*job) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var left atomic.Int32
+	left.Store(int32(len(group)))
+	for _, j := range group {
+		context.AfterFunc(j.ctx, func() {
+			if left.Add(-1) == 0 {
+				cancel()
+			}
+		})
+	}
+	return ctx, cancel
+}
+
+// finalInBatch reports whether a batch failure needs no second try of the
+// same candidate on its own.
+func (d *Downloader) finalInBatch(err error) bool {
+	kind := ErrorKindOf(err)
+	if kind == ErrorUnknown || needsCookies(kind) {
+		return false
+	}
+	return d.policy(kind).Retries == 0 && !d.policy(kind).GiveUp
+}
+
+// parseBatchInfo indexes the downloads yt-dlp reported by URL and video ID.
+func parseBatchInfo(output string) map[string]downloadInfo {
+	infos := map[string]downloadInfo{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var info struct {
+			downloadInfo
+			OriginalURL string `json:"original_url"`
+		}
+		if json.Unmarshal([]byte(line), &info) != nil || info.Filepath == "" {
+			continue
+		}
+		for _, key := range []string{info.OriginalURL, info.WebpageURL, info.ID} {
+			if key != "" {
+				infos[key] = info.downloadInfo
+			}
+		}
+	}
+	return infos
+}
+
+// batchError finds the ERROR: line yt-dlp printed for c, which names the
+// video ID as "[extractor] ID: message".
+func batchError(output string, c Candidate, runErr error) error {
+	if c.ID != "" {
+		var lines []string
+		for _, line := range strings.Split(output, "\n") {
+			if strings.HasPrefix(line, "ERROR:") && strings.Contains(line, "] "+c.ID+":") {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) > 0 {
+			return classifyOutput(strings.Join(lines, "\n"), runErr)
+		}
+	}
+	return &DownloadError{Kind: ErrorUnknown, Message: "no result for " + c.URL + " in batch", Attempts: 1}
+}
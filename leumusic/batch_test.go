@@ -0,0 +1,36 @@
+package leumusic
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGroupBatch(t *testing.T) {
+	newJob := func(c Candidate) *job {
+		return &job{taskRun: &taskRun{ctx: context.Background()}, candidates: []Candidate{c}}
+	}
+	a := newJob(Candidate{ID: "a", URL: "https://www.youtube.com/watch?v=a"})
+	sameURL := newJob(Candidate{ID: "a", URL: "https://www.youtube.com/watch?v=a"})
+	sameID := newJob(Candidate{ID: "a", URL: "https://youtu.be/a"})
+	b := newJob(Candidate{URL: "https://soundcloud.com/x/b"})
+	bAgain := newJob(Candidate{URL: "https://soundcloud.com/x/b"})
+	filtered := newJob(Candidate{ID: "c", URL: "https://www.youtube.com/watch?v=c"})
+	filtered.matchFilter = "duration >=? 60"
+	tried := newJob(Candidate{ID: "d", URL: "https://www.youtube.com/watch?v=d"})
+	tried.next = 1
+
+	var d Downloader
+	groups, single := d.groupBatch([]*job{a, sameURL, sameID, b, bAgain, filtered, tried})
+	if len(groups) != 1 || len(groups[""]) != 2 || groups[""][0] != a || groups[""][1] != b {
+		t.Errorf("groups = %v, want a and b together", groups)
+	}
+	want := []*job{sameURL, sameID, bAgain, filtered, tried}
+	if len(single) != len(want) {
+		t.Fatalf("single has %d jobs, want %d", len(single), len(want))
+	}
+	for i, j := range want {
+		if single[i] != j {
+			t.Errorf("single[%d] = %+v, want %+v", i, single[i].candidates, j.candidates)
+		}
+	}
+}
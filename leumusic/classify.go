@@ -0,0 +1,171 @@
+package leumusic
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrorKind is the category of a failed yt-dlp run.
+type ErrorKind int
+
+const (
+	ErrorUnknown ErrorKind = iota
+	ErrorBotCheck
+	ErrorRateLimited
+	ErrorGeoBlocked
+	ErrorAgeRestricted
+	ErrorUnavailable
+	ErrorNetwork
+	ErrorPostprocess
+	ErrorDisk
+	// ErrorTimeout is a song that took longer than Options.TaskTimeout.
+	ErrorTimeout
+)
+
+var errorKindNames = map[ErrorKind]string{
+	ErrorUnknown:       "unknown",
+	ErrorBotCheck:      "bot-check",
+	ErrorRateLimited:   "rate-limited",
+	ErrorGeoBlocked:    "geo-blocked",
+	ErrorAgeRestricted: "age-restricted",
+	ErrorUnavailable:   "unavailable",
+	ErrorNetwork:       "network",
+	ErrorPostprocess:   "postprocess",
+	ErrorDisk:          "disk",
+	ErrorTimeout:       "timeout",
+}
+
+// String returns a short stable name such as "rate-limited".
+func (k ErrorKind) String() string {
+	return errorKindNames[k]
+}
+
+// ParseErrorKind returns the kind whose String is name.
+func ParseErrorKind(name string) (ErrorKind, bool) {
+	for kind, n := range errorKindNames {
+		if n == name {
+			return kind, true
+		}
+	}
+	return ErrorUnknown, false
+}
+
+// errorPatterns are checked in order, so the more specific ones go first.
+var errorPatterns = []struct {
+	kind     ErrorKind
+	patterns []string
+}{
+	{ErrorDisk, []string{"no space left on device", "disk quota exceeded", "read-only file system", "permission denied", "file name too long"}},
+	{ErrorBotCheck, []string{"confirm you're not a bot", "confirm you’re not a bot", "confirm you are not a bot"}},
+	{ErrorAgeRestricted, []string{"confirm your age", "age-restricted", "age restricted", "inappropriate for some users"}},
+	{ErrorRateLimited, []string{"http error 429", "too many requests", "rate-limit", "rate limit"}},
+	{ErrorGeoBlocked, []string{"not available in your country", "blocked it in your country", "geo restrict", "geo-restrict", "not made this video available in your country"}},
+	{ErrorUnavailable, []string{"video unavailable", "private video", "has been removed", "is not available", "http error 404", "does not exist", "account associated with this video has been terminated"}},
+	{ErrorPostprocess, []string{"postprocessing", "ffmpeg", "ffprobe", "conversion failed"}},
+	{ErrorNetwork, []string{"timed out", "timeout", "connection reset", "connection refused", "name resolution", "network is unreachable", "unable to download webpage", "remote end closed", "http error 5", "ssl"}},
+}
+
+// DownloadError is a classified yt-dlp failure. It matches
+// ErrDownloadFailed with errors.Is.
+type DownloadError struct {
+	Kind    ErrorKind
+	Message string
+	// Attempts is how many times the download was run before giving up.
+	Attempts int
+}
+
+func (e *DownloadError) Error() string {
+	return e.Kind.String() + ": " + e.Message
+}
+
+func (e *DownloadError) Unwrap() error {
+	return ErrDownloadFailed
+}
+
+// ErrorKindOf returns the category of err, or ErrorUnknown.
+func ErrorKindOf(err error) ErrorKind {
+	var de *DownloadError
+	if errors.As(err, &de) {
+		return de.Kind
+	}
+	return ErrorUnknown
+}
+
+// classifyOutput turns the output of a failed yt-dlp run into a
+// DownloadError, using its ERROR: lines when there are any.
+func classifyOutput(output string, runErr error) *DownloadError {
+	var messages []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "ERROR:") {
+			messages = append(messages, strings.TrimSpace(strings.TrimPrefix(line, "ERROR:")))
+		}
+	}
+	if len(messages) == 0 {
+		if line := lastLine(output); line != "" {
+			messages = append(messages, line)
+		} else if runErr != nil {
+			messages = append(messages, runErr.Error())
+		}
+	}
+
+	text := strings.ToLower(strings.Join(messages, "\n"))
+	kind := ErrorUnknown
+	for _, group := range errorPatterns {
+		for _, pattern := range group.patterns {
+			if strings.Contains(text, pattern) {
+				kind = group.kind
+				break
+			}
+		}
+		if kind != ErrorUnknown {
+			break
+		}
+	}
+
+	return &DownloadError{Kind: kind, Message: strings.Join(messages, "; "), Attempts: 1}
+}
+
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// RetryPolicy says how often a failed download is run again before moving
+// on to the next candidate. The wait doubles after every retry, up to
+// MaxBackoff.
+type RetryPolicy struct {
+	Retries    int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// GiveUp stops trying other candidates and providers, for failures
+	// that no other source would fix.
+	GiveUp bool
+}
+
+// DefaultRetryPolicies is used when Options.RetryPolicies is nil. Kinds
+// that depend on the video itself (geo, age, unavailable) are not retried,
+// so the next candidate is tried straight away.
+var DefaultRetryPolicies = map[ErrorKind]RetryPolicy{
+	ErrorUnknown:       {Retries: 1, Backoff: 5 * time.Second},
+	ErrorBotCheck:      {Retries: 1, Backoff: time.Minute},
+	ErrorRateLimited:   {Retries: 3, Backoff: 30 * time.Second, MaxBackoff: 5 * time.Minute},
+	ErrorNetwork:       {Retries: 3, Backoff: 5 * time.Second, MaxBackoff: time.Minute},
+	ErrorPostprocess:   {Retries: 1},
+	ErrorGeoBlocked:    {},
+	ErrorAgeRestricted: {},
+	ErrorUnavailable:   {},
+	ErrorDisk:          {GiveUp: true},
+}
+
+// delay returns how long to wait before retry number n (starting at 1).
+func (p RetryPolicy) delay(n int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < n && d > 0; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return d
+}
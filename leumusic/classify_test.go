@@ -0,0 +1,90 @@
+package leumusic
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClassifyOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		runErr  error
+		kind    ErrorKind
+		message string
+	}{
+		{
+			name:    "bot check",
+			output:  "[youtube] abc: Downloading webpage\nERROR: [youtube] abc: Sign in to confirm you're not a bot. Use --cookies",
+			kind:    ErrorBotCheck,
+			message: "[youtube] abc: Sign in to confirm you're not a bot. Use --cookies",
+		},
+		{
+			name:   "rate limited",
+			output: "ERROR: unable to download video data: HTTP Error 429: Too Many Requests",
+			kind:   ErrorRateLimited,
+		},
+		{
+			name:   "age restricted",
+			output: "ERROR: [youtube] abc: Sign in to confirm your age. This video may be inappropriate for some users.",
+			kind:   ErrorAgeRestricted,
+		},
+		{
+			name:   "geo blocked",
+			output: "ERROR: [youtube] abc: The uploader has not made this video available in your country",
+			kind:   ErrorGeoBlocked,
+		},
+		{
+			name:   "unavailable",
+			output: "ERROR: [youtube] abc: Video unavailable. This video is private",
+			kind:   ErrorUnavailable,
+		},
+		{
+			name:   "disk before postprocess",
+			output: "ERROR: Postprocessing: [Errno 28] No space left on device",
+			kind:   ErrorDisk,
+		},
+		{
+			name:   "postprocess",
+			output: "ERROR: Postprocessing: ffprobe and ffmpeg not found",
+			kind:   ErrorPostprocess,
+		},
+		{
+			name:   "network",
+			output: "ERROR: Unable to download webpage: <urlopen error [Errno -3] Temporary failure in name resolution>",
+			kind:   ErrorNetwork,
+		},
+		{
+			name:    "several errors",
+			output:  "ERROR: first\nWARNING: ignored\nERROR: HTTP Error 429",
+			kind:    ErrorRateLimited,
+			message: "first; HTTP Error 429",
+		},
+		{
+			name:    "no error line",
+			output:  "downloading\nsomething odd happened\n",
+			kind:    ErrorUnknown,
+			message: "something odd happened",
+		},
+		{
+			name:    "no output",
+			runErr:  errors.New("exit status 1"),
+			kind:    ErrorUnknown,
+			message: "exit status 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyOutput(tt.output, tt.runErr)
+			if err.Kind != tt.kind {
+				t.Errorf("kind = %v, want %v", err.Kind, tt.kind)
+			}
+			if tt.message != "" && err.Message != tt.message {
+				t.Errorf("message = %q, want %q", err.Message, tt.message)
+			}
+			if err.Attempts != 1 {
+				t.Errorf("attempts = %d, want 1", err.Attempts)
+			}
+		})
+	}
+}
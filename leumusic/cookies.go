@@ -0,0 +1,217 @@
+package leumusic
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CookieBrowsers lists the browsers yt-dlp can read cookies from.
+var CookieBrowsers = []string{"brave", "chrome", "chromium", "edge", "firefox", "opera", "safari", "vivaldi", "whale"}
+
+// DefaultCookieSource is used when Options.Cookies is the zero value.
+var DefaultCookieSource = CookieSource{Browser: "chrome"}
+
+// CookieSource says where yt-dlp takes cookies from: a browser profile or
+// a Netscape cookies.txt file. The zero value means the default source.
+type CookieSource struct {
+	Browser string
+	Keyring string
+	Profile string
+	// Container is a Firefox container name.
+	Container string
+	// File is a Netscape cookies.txt file, used instead of a browser.
+	File string
+	// Disabled sends no cookies at all, for sites that work better
+	// without them.
+	Disabled bool
+}
+
+// ParseCookieSource reads a source written like yt-dlp's
+// --cookies-from-browser value, BROWSER[+KEYRING][:PROFILE][::CONTAINER],
+// a path to a cookies.txt file, or "none".
+func ParseCookieSource(spec string) (CookieSource, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "":
+		return CookieSource{}, nil
+	case strings.EqualFold(spec, "none"):
+		return CookieSource{Disabled: true}, nil
+	case strings.HasPrefix(spec, "file:"):
+		return CookieSource{File: expandHome(strings.TrimPrefix(spec, "file:"))}, nil
+	case strings.ContainsAny(spec, `/\`) || strings.HasSuffix(strings.ToLower(spec), ".txt"):
+		return CookieSource{File: expandHome(spec)}, nil
+	}
+
+	var src CookieSource
+	rest, container, _ := strings.Cut(spec, "::")
+	src.Container = container
+	rest, src.Profile, _ = strings.Cut(rest, ":")
+	src.Browser, src.Keyring, _ = strings.Cut(rest, "+")
+	src.Browser = strings.ToLower(src.Browser)
+	src.Keyring = strings.ToUpper(src.Keyring)
+
+	known := false
+	for _, b := range CookieBrowsers {
+		known = known || b == src.Browser
+	}
+	if !known {
+		return CookieSource{}, fmt.Errorf("unknown cookie browser %q (use one of %s, or a cookies.txt path)", src.Browser, strings.Join(CookieBrowsers, ", "))
+	}
+	if src.Container != "" && src.Browser != "firefox" {
+		return CookieSource{}, fmt.Errorf("cookie containers only exist in firefox, not %s", src.Browser)
+	}
+	return src, nil
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~"); ok && (rest == "" || os.IsPathSeparator(rest[0])) {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+func (s CookieSource) isZero() bool {
+	return s == CookieSource{}
+}
+
+// String returns the source in the form ParseCookieSource reads.
+func (s CookieSource) String() string {
+	switch {
+	case s.Disabled:
+		return "none"
+	case s.File != "":
+		return s.File
+	case s.Browser == "":
+		return ""
+	}
+	spec := s.Browser
+	if s.Keyring != "" {
+		spec += "+" + s.Keyring
+	}
+	if s.Profile != "" {
+		spec += ":" + s.Profile
+	}
+	if s.Container != "" {
+		spec += "::" + s.Container
+	}
+	return spec
+}
+
+// args returns the yt-dlp arguments that load these cookies.
+func (s CookieSource) args() []string {
+	switch {
+	case s.Disabled:
+		return nil
+	case s.File != "":
+		return []string{"--cookies", s.File}
+	}
+	return []string{"--cookies-from-browser", s.String()}
+}
+
+// providerSites maps search prefixes to the site they search.
+var providerSites = map[Provider]string{
+	ProviderYouTube:      "youtube.com",
+	ProviderYouTubeMusic: "youtube.com",
+	ProviderSoundCloud:   "soundcloud.com",
+}
+
+// siteOf returns the host a yt-dlp target is on: the host of a URL, or the
+// site of a search such as "ytsearch5:query".
+func siteOf(target string) string {
+	if strings.Contains(target, "://") {
+		if u, err := url.Parse(target); err == nil {
+			return strings.ToLower(u.Hostname())
+		}
+		return ""
+	}
+	prefix, _, _ := strings.Cut(target, ":")
+	return providerSites[Provider(strings.TrimRight(prefix, "0123456789"))]
+}
+
+// cookieSource picks the source for target: the Options.SiteCookies entry
+// for the most specific matching domain, else Options.Cookies.
+func (d *Downloader) cookieSource(target string) CookieSource {
+	site := siteOf(target)
+	best, bestLen := d.opts.Cookies, -1
+	for domain, src := range d.opts.SiteCookies {
+		domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+		if (site == domain || strings.HasSuffix(site, "."+domain)) && len(domain) > bestLen {
+			best, bestLen = src, len(domain)
+		}
+	}
+	if best.isZero() {
+		return DefaultCookieSource
+	}
+	return best
+}
+
+// CheckCookies reads the cookies of src the same way a download would and
+// returns how many belong to site, or to any site if site is empty.
+func CheckCookies(tools *Toolbox, src CookieSource, site string) (int, error) {
+	if src.Disabled {
+		return 0, nil
+	}
+	if src.isZero() {
+		src = DefaultCookieSource
+	}
+	if src.File != "" {
+		return countCookies(src.File, site)
+	}
+
+	// yt-dlp has no command to only read cookies, but it saves the ones it
+	// loaded to --cookies on exit even when there is nothing to download.
+	dir, err := os.MkdirTemp("", "leumusic-cookies")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "cookies.txt")
+
+	output, err := tools.Run("yt-dlp", "--ignore-config", "--cookies-from-browser", src.String(), "--cookies", file)
+	if errors.Is(err, ErrToolMissing) {
+		return 0, err
+	}
+	n, readErr := countCookies(file, site)
+	if readErr != nil {
+		if line := classifyOutput(string(output), err).Message; line != "" {
+			return 0, errors.New(line)
+		}
+		return 0, readErr
+	}
+	return n, nil
+}
+
+// countCookies counts the entries of a Netscape cookies.txt file whose
+// domain is site or one of its subdomains.
+func countCookies(path, site string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	n := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimPrefix(scanner.Text(), "#HttpOnly_")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return n, fmt.Errorf("%s: not a Netscape cookies.txt file", path)
+		}
+		domain := strings.TrimPrefix(strings.ToLower(fields[0]), ".")
+		if site == "" || domain == site || strings.HasSuffix(domain, "."+site) || strings.HasSuffix(site, "."+domain) {
+			n++
+		}
+	}
+	return n, scanner.Err()
+}
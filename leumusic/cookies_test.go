@@ -0,0 +1,149 @@
+package leumusic
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCookieSource(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		spec string
+		want CookieSource
+	}{
+		{"", CookieSource{}},
+		{"none", CookieSource{Disabled: true}},
+		{"NONE", CookieSource{Disabled: true}},
+		{"chrome", CookieSource{Browser: "chrome"}},
+		{"Firefox", CookieSource{Browser: "firefox"}},
+		{"chrome+gnomekeyring", CookieSource{Browser: "chrome", Keyring: "GNOMEKEYRING"}},
+		{"chrome:Profile 1", CookieSource{Browser: "chrome", Profile: "Profile 1"}},
+		{"firefox::Work", CookieSource{Browser: "firefox", Container: "Work"}},
+		{"firefox+basictext:default-release::Personal", CookieSource{Browser: "firefox", Keyring: "BASICTEXT", Profile: "default-release", Container: "Personal"}},
+		{"cookies.txt", CookieSource{File: "cookies.txt"}},
+		{"Cookies.TXT", CookieSource{File: "Cookies.TXT"}},
+		{"/tmp/yt", CookieSource{File: "/tmp/yt"}},
+		{`C:\cookies\yt`, CookieSource{File: `C:\cookies\yt`}},
+		{"file:yt-cookies", CookieSource{File: "yt-cookies"}},
+		{"~/cookies.txt", CookieSource{File: filepath.Join(home, "cookies.txt")}},
+		{"~other/cookies.txt", CookieSource{File: "~other/cookies.txt"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseCookieSource(tt.spec)
+		if err != nil {
+			t.Errorf("ParseCookieSource(%q) error: %v", tt.spec, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseCookieSource(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseCookieSourceErrors(t *testing.T) {
+	tests := []struct {
+		spec    string
+		message string
+	}{
+		{"netscape", `unknown cookie browser "netscape"`},
+		{"+KEYRING", `unknown cookie browser ""`},
+		{"chrome::Work", "only exist in firefox, not chrome"},
+	}
+	for _, tt := range tests {
+		if _, err := ParseCookieSource(tt.spec); err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("ParseCookieSource(%q) error = %v, want %q", tt.spec, err, tt.message)
+		}
+	}
+}
+
+func TestCookieSourceString(t *testing.T) {
+	for _, spec := range []string{"none", "chrome", "chrome+GNOMEKEYRING", "chrome:Profile 1", "firefox::Work", "firefox+BASICTEXT:default-release::Personal", "/tmp/cookies.txt"} {
+		src, err := ParseCookieSource(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := src.String(); got != spec {
+			t.Errorf("ParseCookieSource(%q).String() = %q", spec, got)
+		}
+	}
+}
+
+func TestCookieSourceForSite(t *testing.T) {
+	d := &Downloader{opts: Options{
+		Cookies: CookieSource{Browser: "firefox"},
+		SiteCookies: map[string]CookieSource{
+			"youtube.com":        {File: "/tmp/yt.txt"},
+			".music.youtube.com": {Browser: "chrome"},
+			"soundcloud.com":     {Disabled: true},
+		},
+	}}
+	tests := []struct {
+		target string
+		want   CookieSource
+	}{
+		{"https://www.youtube.com/watch?v=abc", CookieSource{File: "/tmp/yt.txt"}},
+		{"https://YouTube.com/watch?v=abc", CookieSource{File: "/tmp/yt.txt"}},
+		{"https://music.youtube.com/watch?v=abc", CookieSource{Browser: "chrome"}},
+		{"ytsearch5:Queen Innuendo", CookieSource{File: "/tmp/yt.txt"}},
+		{"https://soundcloud.com/queen/innuendo", CookieSource{Disabled: true}},
+		{"scsearch3:Queen Innuendo", CookieSource{Disabled: true}},
+		{"https://notyoutube.com/v/abc", CookieSource{Browser: "firefox"}},
+		{"https://vimeo.com/123", CookieSource{Browser: "firefox"}},
+	}
+	for _, tt := range tests {
+		if got := d.cookieSource(tt.target); got != tt.want {
+			t.Errorf("cookieSource(%q) = %+v, want %+v", tt.target, got, tt.want)
+		}
+	}
+
+	if got := (&Downloader{}).cookieSource("https://www.youtube.com/watch?v=abc"); got != DefaultCookieSource {
+		t.Errorf("cookieSource with no cookies set = %+v, want the default %+v", got, DefaultCookieSource)
+	}
+}
+
+func TestCountCookies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	cookies := "# Netscape HTTP Cookie File\n" +
+		"# This is a generated file! Do not edit.\n" +
+		"\n" +
+		".youtube.com\tTRUE\t/\tTRUE\t0\tSID\ta\n" +
+		"#HttpOnly_.youtube.com\tTRUE\t/\tTRUE\t0\tHSID\tb\n" +
+		"music.youtube.com\tFALSE\t/\tTRUE\t0\tPREF\tc\n" +
+		".google.com\tTRUE\t/\tTRUE\t0\tNID\td\n" +
+		"notyoutube.com\tFALSE\t/\tFALSE\t0\tX\te\n"
+	if err := os.WriteFile(path, []byte(cookies), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		site string
+		want int
+	}{
+		{"", 5},
+		{"youtube.com", 3},
+		{"music.youtube.com", 3},
+		{"google.com", 1},
+		{"soundcloud.com", 0},
+	}
+	for _, tt := range tests {
+		got, err := countCookies(path, tt.site)
+		if err != nil {
+			t.Errorf("countCookies(%q) error: %v", tt.site, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("countCookies(%q) = %d, want %d", tt.site, got, tt.want)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("SID=a; HSID=b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := countCookies(path, ""); err == nil || !strings.Contains(err.Error(), "not a Netscape cookies.txt file") {
+		t.Errorf("countCookies on a header dump error = %v", err)
+	}
+}
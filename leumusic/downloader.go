@@ -0,0 +1,629 @@
+// Package leumusic downloads songs as MP3 files with yt-dlp and files them
+// into one folder per artist. The leumusic command is a thin client of it.
+package leumusic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Quality selects the yt-dlp audio quality / speed trade-off.
+type Quality int
+
+const (
+	QualityNormal Quality = iota
+	QualityTurbo
+	QualityHigh
+)
+
+// String returns the name stored in ledger records.
+func (q Quality) String() string {
+	switch q {
+	case QualityTurbo:
+		return "turbo"
+	case QualityHigh:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
+func (q Quality) audioQuality() string {
+	switch q {
+	case QualityTurbo:
+		return "9"
+	case QualityHigh:
+		return "1"
+	default:
+		return "5"
+	}
+}
+
+// DefaultStickyCookiesRate is used when Options.StickyCookiesRate is zero.
+const DefaultStickyCookiesRate = 0.3
+
+// ErrDownloadFailed wraps every failure reported by yt-dlp.
+var ErrDownloadFailed = errors.New("download failed")
+
+// ErrTaskCancelled is the error of a task stopped with Downloader.Cancel.
+var ErrTaskCancelled = errors.New("task cancelled")
+
+// DefaultTaskTimeout is how long one song may take when
+// Options.TaskTimeout is zero.
+const DefaultTaskTimeout = 30 * time.Minute
+
+// Options configures a Downloader. The zero value downloads into the
+// current directory with default workers and no ledger.
+type Options struct {
+	// OutputDir is where artist folders are created. Defaults to ".".
+	OutputDir string
+	// Workers fixes the number of concurrent downloads. 0 starts with
+	// DefaultWorkers and adapts the count between 1 and MaxWorkers while
+	// the batch runs, from throughput, error rate, CPU load and memory.
+	Workers int
+	// MaxWorkers caps an adaptive pool. 0 uses the package's MaxWorkers.
+	MaxWorkers int
+	// ResolveWorkers is how many searches run at once. 0 uses
+	// DefaultResolveWorkers.
+	ResolveWorkers int
+	// TranscodeWorkers is how many ffmpeg conversions run at once. 0 uses
+	// one per CPU.
+	TranscodeWorkers int
+	// QueueSize bounds the queue in front of each stage. 0 uses
+	// DefaultQueueSize.
+	QueueSize int
+	// BatchSize above 1 fetches up to that many songs with one yt-dlp
+	// process, waiting at most BatchWait (0 uses DefaultBatchWait) for a
+	// batch to fill. Songs that fail in a batch are retried on their own.
+	BatchSize int
+	BatchWait time.Duration
+	// TaskTimeout limits how long one song may take, from its search to
+	// its MP3. Tools still running then are killed and the song fails with
+	// ErrorTimeout. 0 uses DefaultTaskTimeout; a negative value sets no
+	// limit. To limit a whole Download, give it a context with a deadline.
+	TaskTimeout time.Duration
+	// AdjustEvery is how often an adaptive pool is resized. 0 uses
+	// DefaultAdjustEvery.
+	AdjustEvery time.Duration
+	Quality     Quality
+	// UseCookies passes browser cookies to yt-dlp for every task.
+	UseCookies bool
+	// EscalateCookies retries a task with browser cookies when it hits a
+	// bot check or age restriction without them.
+	EscalateCookies bool
+	// StickyCookiesRate is the share of yt-dlp runs blocked by a bot check
+	// or age restriction above which every later task starts with cookies.
+	// 0 uses DefaultStickyCookiesRate. Only used with EscalateCookies.
+	StickyCookiesRate float64
+	// Cookies is where cookies are read from when they are used. The zero
+	// value uses DefaultCookieSource.
+	Cookies CookieSource
+	// SiteCookies overrides Cookies for some sites, keyed by domain such
+	// as "soundcloud.com". Subdomains match too.
+	SiteCookies map[string]CookieSource
+	// Throttle limits how fast yt-dlp runs are started. Nil uses
+	// DefaultThrottle.
+	Throttle *Throttle
+	// Tools locates yt-dlp and ffmpeg. nil uses automatic discovery.
+	Tools *Toolbox
+	// Ledger, if set, is consulted before searching and receives a Record
+	// after every successful search download.
+	Ledger Ledger
+	// Match decides whether a file already on disk is the requested song.
+	Match Matcher
+	// Filter rejects unwanted search results. nil uses DefaultFilter; each
+	// task's own Filter is merged on top.
+	Filter *Filter
+	// Providers are searched in order; when every candidate of one fails to
+	// download, the next provider is tried. nil searches only YouTube.
+	Providers []Provider
+	// RetryPolicies says how each kind of failure is retried. nil uses
+	// DefaultRetryPolicies; kinds missing from the map are not retried.
+	RetryPolicies map[ErrorKind]RetryPolicy
+	// SearchResults is how many search results are scored before picking
+	// one to download. 0 uses DefaultSearchResults; 1 takes the first hit.
+	SearchResults int
+	// UnnamedFolder is used when an artist name sanitizes to nothing.
+	UnnamedFolder string
+	// OnEvent receives progress events. It is called from worker
+	// goroutines and must be safe for concurrent use.
+	OnEvent func(Event)
+}
+
+// EventKind tells what an Event reports.
+type EventKind int
+
+const (
+	EventStarted EventKind = iota
+	EventSkipped
+	EventSucceeded
+	EventFailed
+	// EventLedgerError reports that a successful download could not be
+	// recorded in the Ledger. Err holds the cause.
+	EventLedgerError
+	// EventResolved reports which search result was picked for a song.
+	// Candidates holds every result scored, best (the one downloaded) first.
+	// Err is set when the filter rejected them all and nothing is downloaded.
+	// There is one such event per provider searched.
+	EventResolved
+	// EventFallback reports that downloading Candidates[0] failed with Err.
+	// The remaining candidates, then the next providers, are tried after it.
+	EventFallback
+	// EventRetrying reports that attempt number Attempt failed with Err,
+	// a *DownloadError, and will be run again after Wait.
+	EventRetrying
+	// EventCookiesEscalated reports that the task failed with Err and is
+	// being retried with browser cookies.
+	EventCookiesEscalated
+	// EventCookiesSticky reports that every later task will use cookies.
+	// It is sent once, with Index -1.
+	EventCookiesSticky
+	// EventPaused reports that Attempt rate-limit or bot-check errors in a
+	// row, the last one Err, paused every worker for Wait.
+	EventPaused
+	// EventResumed reports that workers run again after a pause of Wait.
+	// It has Index -1.
+	EventResumed
+)
+
+// Event reports progress for the task at Index in the slice passed to
+// Download.
+type Event struct {
+	Kind       EventKind
+	Index      int
+	Task       DownloadTask
+	Err        error
+	Candidates []Candidate
+	Attempt    int
+	Wait       time.Duration
+}
+
+// Status is the final state of a task.
+type Status int
+
+const (
+	StatusSucceeded Status = iota
+	StatusSkipped
+	StatusFailed
+)
+
+// Result is the outcome of one DownloadTask.
+type Result struct {
+	Task     DownloadTask
+	Status   Status
+	Err      error
+	Duration time.Duration
+}
+
+// Downloader runs DownloadTasks concurrently. It holds no global state, so
+// several Downloaders may be used side by side.
+type Downloader struct {
+	opts     Options
+	folderMu sync.Mutex
+	limiter  *limiter
+	adaptive bool
+	workers  atomic.Int32
+	stages   [stageCount]stageCounter
+
+	finished   atomic.Int64
+	failedRuns atomic.Int64
+
+	runs          atomic.Int64
+	blocked       atomic.Int64
+	stickyCookies atomic.Bool
+
+	// cancels holds what stops each task of the running Download, until
+	// it finishes.
+	tasksMu sync.Mutex
+	cancels []context.CancelCauseFunc
+}
+
+// New returns a Downloader, filling in defaults for unset options.
+func New(opts Options) *Downloader {
+	if opts.OutputDir == "" {
+		opts.OutputDir = "."
+	}
+	adaptive := opts.Workers <= 0
+	if opts.MaxWorkers <= 0 {
+		opts.MaxWorkers = MaxWorkers()
+	}
+	if adaptive {
+		opts.Workers = min(DefaultWorkers(opts.Quality), opts.MaxWorkers)
+	}
+	if opts.AdjustEvery <= 0 {
+		opts.AdjustEvery = DefaultAdjustEvery
+	}
+	if opts.ResolveWorkers <= 0 {
+		opts.ResolveWorkers = DefaultResolveWorkers
+	}
+	if opts.TranscodeWorkers <= 0 {
+		opts.TranscodeWorkers = SystemCPUs()
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	if opts.BatchWait <= 0 {
+		opts.BatchWait = DefaultBatchWait
+	}
+	if opts.TaskTimeout == 0 {
+		opts.TaskTimeout = DefaultTaskTimeout
+	}
+	if opts.Tools == nil {
+		opts.Tools = &Toolbox{}
+	}
+	if opts.Filter == nil {
+		filter := DefaultFilter()
+		opts.Filter = &filter
+	}
+	if opts.StickyCookiesRate <= 0 {
+		opts.StickyCookiesRate = DefaultStickyCookiesRate
+	}
+	if opts.RetryPolicies == nil {
+		opts.RetryPolicies = DefaultRetryPolicies
+	}
+	if len(opts.Providers) == 0 {
+		opts.Providers = []Provider{ProviderYouTube}
+	}
+	if opts.SearchResults <= 0 {
+		opts.SearchResults = DefaultSearchResults
+	}
+	if opts.UnnamedFolder == "" {
+		opts.UnnamedFolder = "UnnamedFolder"
+	}
+	if opts.Throttle == nil {
+		throttle := DefaultThrottle()
+		opts.Throttle = &throttle
+	}
+	d := &Downloader{opts: opts, limiter: newLimiter(*opts.Throttle), adaptive: adaptive}
+	d.workers.Store(int32(opts.Workers))
+	return d
+}
+
+// Workers returns the current number of concurrent downloads. It changes
+// during Download when the pool is adaptive.
+func (d *Downloader) Workers() int {
+	return int(d.workers.Load())
+}
+
+// RecommendedWorkers suggests a worker count for this machine.
+func RecommendedWorkers() int {
+	cpus := SystemCPUs()
+	memoryGB := SystemMemory() >> 30
+
+	workers := cpus * 2
+
+	if memoryGB >= 16 {
+		workers = cpus * 3
+	} else if memoryGB >= 8 {
+		workers = cpus * 2
+	} else {
+		workers = cpus
+	}
+
+	if workers < 4 {
+		workers = 4
+	}
+	if workers > 12 {
+		workers = 12
+	}
+
+	return min(workers, MaxWorkers())
+}
+
+// DefaultWorkers is the worker count used when Options.Workers is 0.
+func DefaultWorkers(q Quality) int {
+	if q == QualityTurbo {
+		return RecommendedWorkers()
+	}
+	cpus := SystemCPUs()
+	workers := cpus * 2
+	if workers < 4 {
+		workers = 4
+	}
+	if workers > 10 {
+		workers = 10
+	}
+	return workers
+}
+
+func (d *Downloader) emit(e Event) {
+	if d.opts.OnEvent != nil {
+		d.opts.OnEvent(e)
+	}
+}
+
+// downloadInfo is what yt-dlp reports about a finished download.
+type downloadInfo struct {
+	ID          string  `json:"id"`
+	WebpageURL  string  `json:"webpage_url"`
+	Filepath    string  `json:"filepath"`
+	Duration    float64 `json:"duration"`
+	Title       string  `json:"title"`
+	Artist      string  `json:"artist"`
+	Uploader    string  `json:"uploader"`
+	Album       string  `json:"album"`
+	ReleaseYear int     `json:"release_year"`
+
+	Provider Provider `json:"-"`
+	Rank     int      `json:"-"`
+}
+
+const infoTemplate = "after_move:%(.{id,webpage_url,original_url,filepath,duration,title,artist,uploader,album,release_year})j"
+
+func parseDownloadInfo(output string) downloadInfo {
+	var info downloadInfo
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "{") {
+			json.Unmarshal([]byte(line), &info)
+		}
+	}
+	return info
+}
+
+func (d *Downloader) record(t DownloadTask, info downloadInfo) Record {
+	rec := Record{
+		Artist:    t.Artist,
+		Song:      t.Song,
+		VideoID:   info.ID,
+		SourceURL: info.WebpageURL,
+		FilePath:  info.Filepath,
+		Format:    string(t.format()),
+		Quality:   d.opts.Quality.String(),
+		Duration:  info.Duration,
+		Provider:  string(info.Provider),
+		Rank:      info.Rank,
+	}
+	if rec.FilePath != "" {
+		if stat, err := os.Stat(rec.FilePath); err == nil {
+			rec.Size = stat.Size()
+		}
+	}
+	return rec
+}
+
+// artistFolder returns the folder for artist, or a playlist, creating it
+// if needed.
+func (d *Downloader) artistFolder(artist string) string {
+	d.folderMu.Lock()
+	defer d.folderMu.Unlock()
+	return Folder(d.opts.OutputDir, artist, d.opts.UnnamedFolder)
+}
+
+// Folder returns the folder under dir for name, an artist or playlist,
+// reusing an existing folder whose name only differs in case or accents.
+// It is created if there is none.
+func Folder(dir, name, unnamed string) string {
+	folder, ok := FindFolder(dir, name, unnamed)
+	if !ok {
+		os.MkdirAll(folder, 0755)
+	}
+	return folder
+}
+
+// FindFolder is Folder without creating it: ok reports whether the
+// returned folder exists.
+func FindFolder(dir, name, unnamed string) (string, bool) {
+	name = SanitizeFolderName(name, unnamed)
+	folder := filepath.Join(dir, name)
+	if _, err := os.Stat(folder); err == nil {
+		return folder, true
+	}
+
+	if entries, err := os.ReadDir(dir); err == nil {
+		want := NormalizeTitle(name)
+		for _, entry := range entries {
+			if entry.IsDir() && NormalizeTitle(entry.Name()) == want {
+				return filepath.Join(dir, entry.Name()), true
+			}
+		}
+	}
+	return folder, false
+}
+
+// taskRun is what one task carries across its yt-dlp runs.
+type taskRun struct {
+	// ctx is the task's own, derived from the one Download was called
+	// with. Once it is done, running tools are killed and no more are
+	// started; its cause says why.
+	ctx   context.Context
+	task  DownloadTask
+	index int
+	// cookies is set once the task escalated to browser cookies.
+	cookies bool
+}
+
+func (d *Downloader) policy(kind ErrorKind) RetryPolicy {
+	return d.opts.RetryPolicies[kind]
+}
+
+// fatal reports whether err should stop the task instead of moving on to
+// the next candidate.
+func (d *Downloader) fatal(err error) bool {
+	switch {
+	case errors.Is(err, ErrToolMissing), errors.Is(err, ErrTaskCancelled), ErrorKindOf(err) == ErrorTimeout,
+		errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return true
+	}
+	var de *DownloadError
+	return errors.As(err, &de) && d.policy(de.Kind).GiveUp
+}
+
+// needsCookies reports whether a failure of this kind may go away when
+// signed in through browser cookies.
+func needsCookies(kind ErrorKind) bool {
+	return kind == ErrorBotCheck || kind == ErrorAgeRestricted
+}
+
+// retry runs fn, which runs tool, again while it fails with a
+// DownloadError whose retry policy allows it, waiting the policy's backoff
+// in between. A bot check or age restriction without cookies is first
+// retried at once with cookies, if EscalateCookies is set. Only yt-dlp runs
+// count toward the throttle's breaker and the blocked rate, since ffmpeg
+// never talks to the sites.
+func (d *Downloader) retry(run *taskRun, tool string, fn func() error) error {
+	t, index := run.task, run.index
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if run.ctx.Err() != nil {
+			return context.Cause(run.ctx)
+		}
+		if tool == "yt-dlp" {
+			d.runs.Add(1)
+			if streak := d.limiter.report(err); streak > 0 {
+				d.emit(Event{Kind: EventPaused, Index: index, Task: t, Err: err, Attempt: streak, Wait: d.opts.Throttle.Cooldown})
+			}
+		}
+		var de *DownloadError
+		if !errors.As(err, &de) {
+			return err
+		}
+		de.Attempts = attempt
+		switch de.Kind {
+		case ErrorRateLimited, ErrorBotCheck, ErrorNetwork, ErrorUnknown:
+			d.failedRuns.Add(1)
+		}
+
+		if needsCookies(de.Kind) {
+			d.noteBlocked()
+			if d.opts.EscalateCookies && !run.cookies && !t.NoCookies {
+				run.cookies = true
+				d.emit(Event{Kind: EventCookiesEscalated, Index: index, Task: t, Err: err})
+				attempt--
+				continue
+			}
+		}
+
+		policy := d.policy(de.Kind)
+		if attempt > policy.Retries {
+			return err
+		}
+		wait := policy.delay(attempt)
+		d.emit(Event{Kind: EventRetrying, Index: index, Task: t, Err: err, Attempt: attempt, Wait: wait})
+		if !sleep(run.ctx, wait) {
+			return context.Cause(run.ctx)
+		}
+	}
+}
+
+// stickyCookiesMinRuns is how many yt-dlp runs are needed before the
+// blocked rate is trusted.
+const stickyCookiesMinRuns = 5
+
+// noteBlocked counts a bot check or age restriction and turns cookies on
+// for every later task once they are too frequent.
+func (d *Downloader) noteBlocked() {
+	blocked := d.blocked.Add(1)
+	if !d.opts.EscalateCookies || d.opts.UseCookies {
+		return
+	}
+	runs := d.runs.Load()
+	if runs < stickyCookiesMinRuns || float64(blocked)/float64(runs) < d.opts.StickyCookiesRate {
+		return
+	}
+	if d.stickyCookies.CompareAndSwap(false, true) {
+		d.emit(Event{Kind: EventCookiesSticky, Index: -1})
+	}
+}
+
+// Cancel stops the task at index of the running Download: its tools are
+// killed and it fails with ErrTaskCancelled. It reports false if there is
+// no such task or it already finished.
+func (d *Downloader) Cancel(index int) bool {
+	d.tasksMu.Lock()
+	defer d.tasksMu.Unlock()
+
+	if index < 0 || index >= len(d.cancels) || d.cancels[index] == nil {
+		return false
+	}
+	d.cancels[index](ErrTaskCancelled)
+	return true
+}
+
+// taskContexts gives every task a context of its own, so Cancel can stop
+// one without the others.
+func (d *Downloader) taskContexts(ctx context.Context, n int) []context.Context {
+	d.tasksMu.Lock()
+	defer d.tasksMu.Unlock()
+
+	contexts := make([]context.Context, n)
+	d.cancels = make([]context.CancelCauseFunc, n)
+	for i := range contexts {
+		contexts[i], d.cancels[i] = context.WithCancelCause(ctx)
+	}
+	return contexts
+}
+
+// release frees the context of a task that finished.
+func (d *Downloader) release(index int) {
+	d.tasksMu.Lock()
+	defer d.tasksMu.Unlock()
+
+	if cancel := d.cancels[index]; cancel != nil {
+		cancel(nil)
+		d.cancels[index] = nil
+	}
+}
+
+// StickyCookies reports whether so many runs were blocked that cookies were
+// turned on for every task. Callers may keep using cookies afterwards.
+func (d *Downloader) StickyCookies() bool {
+	return d.stickyCookies.Load()
+}
+
+// runYtDlp runs yt-dlp once the throttle lets it.
+func (d *Downloader) runYtDlp(ctx context.Context, args ...string) ([]byte, error) {
+	if paused := d.limiter.wait(ctx); paused > 0 {
+		d.emit(Event{Kind: EventResumed, Index: -1, Wait: paused})
+	}
+	return d.opts.Tools.RunContext(ctx, "yt-dlp", args...)
+}
+
+func (d *Downloader) isAlreadyDownloaded(artist, song, folder string) (string, bool) {
+	if d.opts.Ledger != nil && d.opts.Ledger.Has(artist, song) {
+		return "", true
+	}
+
+	var files []string
+	for _, format := range []AudioFormat{FormatMP3, FormatFLAC} {
+		found, _ := filepath.Glob(filepath.Join(folder, "*."+string(format)))
+		files = append(files, found...)
+	}
+
+	best, bestScore := "", 0.0
+	for _, file := range files {
+		if score, ok := d.opts.Match.MatchFile(artist, song, file); ok && score > bestScore {
+			best, bestScore = file, score
+		}
+	}
+
+	return best, best != ""
+}
+
+var invalidFolderChars = regexp.MustCompile(`[<>:"/\\|?*]`)
+
+// SanitizeFolderName makes name safe to use as a folder on every OS,
+// returning unnamed if nothing usable is left.
+func SanitizeFolderName(name, unnamed string) string {
+	name = invalidFolderChars.ReplaceAllString(name, "_")
+	name = strings.TrimRight(name, ". ")
+	name = strings.TrimSpace(name)
+
+	if len(name) > 50 {
+		name = name[:50]
+	}
+
+	if name == "" {
+		name = unnamed
+	}
+
+	return name
+}
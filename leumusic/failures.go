@@ -0,0 +1,206 @@
+package leumusic
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultParkAfter is how many failed runs park a song when SetParkAfter
+// wasn't called.
+const DefaultParkAfter = 3
+
+// Failure is a song that failed to download, why, and how many runs in a
+// row it failed.
+type Failure struct {
+	DownloadTask
+	// Reason is the ErrorKind name of the last failure, and Error its
+	// message.
+	Reason   string `json:"reason"`
+	Error    string `json:"error"`
+	Attempts int    `json:"attempts"`
+	// Parked songs failed too often to be worth retrying with the rest.
+	Parked      bool      `json:"parked,omitempty"`
+	FirstFailed time.Time `json:"first_failed"`
+	LastFailed  time.Time `json:"last_failed"`
+}
+
+// Failures keeps the songs that failed across runs in a JSON file, so
+// they can be retried later. A song leaves it once it downloads.
+type Failures struct {
+	path      string
+	mu        sync.Mutex
+	entries   []Failure
+	parkAfter int
+}
+
+// OpenFailures loads the list at path. A missing file is an empty list.
+func OpenFailures(path string) (*Failures, error) {
+	f := &Failures{path: path, parkAfter: DefaultParkAfter}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return f, err
+	}
+	return f, json.Unmarshal(data, &f.entries)
+}
+
+// Path returns the file backing the list.
+func (f *Failures) Path() string {
+	return f.path
+}
+
+// SetParkAfter changes how many failed runs park a song. Zero or less
+// never parks.
+func (f *Failures) SetParkAfter(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.parkAfter = n
+}
+
+func failureKey(t DownloadTask) string {
+	if t.Artist == "" && t.Song == "" {
+		return t.URL
+	}
+	return normalizedKey(t.Artist, t.Song)
+}
+
+func (f *Failures) find(task DownloadTask) int {
+	key := failureKey(task)
+	for i, e := range f.entries {
+		if failureKey(e.DownloadTask) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get returns the entry for task, if it failed before.
+func (f *Failures) Get(task DownloadTask) (Failure, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if i := f.find(task); i >= 0 {
+		return f.entries[i], true
+	}
+	return Failure{}, false
+}
+
+// Add records that task failed with err and returns its updated entry.
+func (f *Failures) Add(task DownloadTask, err error) Failure {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now().UTC()
+	i := f.find(task)
+	if i < 0 {
+		f.entries = append(f.entries, Failure{FirstFailed: now})
+		i = len(f.entries) - 1
+	}
+	e := &f.entries[i]
+	e.DownloadTask = task
+	e.Reason = ErrorKindOf(err).String()
+	e.Error = err.Error()
+	var de *DownloadError
+	if errors.As(err, &de) {
+		e.Error = de.Message
+	}
+	e.Attempts++
+	e.LastFailed = now
+	e.Parked = f.parkAfter > 0 && e.Attempts >= f.parkAfter
+	return *e
+}
+
+// Remove drops task from the list, and reports whether it was there.
+func (f *Failures) Remove(task DownloadTask) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i := f.find(task)
+	if i < 0 {
+		return false
+	}
+	f.entries = append(f.entries[:i], f.entries[i+1:]...)
+	return true
+}
+
+// Unpark gives every parked song another chance. Their attempts are kept,
+// so one more failure parks them again.
+func (f *Failures) Unpark() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.entries {
+		f.entries[i].Parked = false
+	}
+}
+
+// Entries returns every failed song in the order it first failed.
+func (f *Failures) Entries() []Failure {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Failure(nil), f.entries...)
+}
+
+// Pending returns the songs worth retrying: every one that isn't parked.
+func (f *Failures) Pending() []DownloadTask {
+	var tasks []DownloadTask
+	for _, e := range f.Entries() {
+		if !e.Parked {
+			tasks = append(tasks, e.DownloadTask)
+		}
+	}
+	return tasks
+}
+
+// Save writes the list to its file, or removes the file when the list is
+// empty.
+func (f *Failures) Save() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.entries) == 0 {
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(f.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(f.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
+
+// WriteCSV writes the list as CSV with a header row.
+func (f *Failures) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"artist", "song", "url", "reason", "error", "attempts", "parked", "first_failed", "last_failed"})
+	for _, e := range f.Entries() {
+		cw.Write([]string{
+			e.Artist, e.Song, e.URL, e.Reason, e.Error,
+			strconv.Itoa(e.Attempts), strconv.FormatBool(e.Parked),
+			e.FirstFailed.Format(time.RFC3339), e.LastFailed.Format(time.RFC3339),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,54 @@
+package leumusic
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFailures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "failed.json")
+	f, err := OpenFailures(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetParkAfter(2)
+
+	song := DownloadTask{Artist: "Queen", Song: "Innuendo", Format: FormatFLAC, Playlist: "Mix", Duration: 391 * time.Second}
+	other := DownloadTask{URL: "https://www.youtube.com/watch?v=abc", NoCookies: true}
+	f.Add(song, &DownloadError{Kind: ErrorUnavailable, Message: "Video unavailable"})
+	f.Add(other, errors.New("boom"))
+	if e := f.Add(DownloadTask{Artist: "queen", Song: "innuendo", Format: FormatFLAC, Playlist: "Mix", Duration: 391 * time.Second}, &DownloadError{Kind: ErrorNetwork, Message: "timed out"}); !e.Parked || e.Attempts != 2 {
+		t.Errorf("second failure = %+v, want parked after 2 attempts", e)
+	}
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err = OpenFailures(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, ok := f.Get(song)
+	if !ok || e.Reason != "network" || e.Error != "timed out" || e.Attempts != 2 || !e.Parked {
+		t.Errorf("Get(%q) = %+v, %v", song.Key(), e, ok)
+	}
+	if got, want := f.Pending(), []DownloadTask{other}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Pending() = %+v, want %+v", got, want)
+	}
+
+	var csv strings.Builder
+	if err := f.WriteCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(csv.String(), "artist,song,url,reason,error,attempts,parked,") || !strings.Contains(csv.String(), "\nqueen,innuendo,,network,timed out,2,true,") {
+		t.Errorf("WriteCSV wrote:\n%s", csv.String())
+	}
+
+	if !f.Remove(song) || f.Remove(song) {
+		t.Error("Remove should drop the song once")
+	}
+}
@@ -0,0 +1,140 @@
+package leumusic
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultExclude lists the words that mark a version nobody asked for.
+var DefaultExclude = []string{"live", "cover", "karaoke", "8d", "slowed", "sped up", "remix"}
+
+// Default duration bounds for search results.
+const (
+	DefaultMinDuration = 60 * time.Second
+	DefaultMaxDuration = 15 * time.Minute
+)
+
+// Filter rejects search results before they are scored. A word in Exclude
+// only rejects a result when the requested song doesn't contain it too;
+// every word in Include must appear in the title. Zero durations disable
+// that bound, and results of unknown length are never rejected by it.
+type Filter struct {
+	Include     []string      `json:"include,omitempty"`
+	Exclude     []string      `json:"exclude,omitempty"`
+	MinDuration time.Duration `json:"min_duration,omitempty"`
+	MaxDuration time.Duration `json:"max_duration,omitempty"`
+}
+
+// DefaultFilter returns the filter used when none is configured.
+func DefaultFilter() Filter {
+	return Filter{
+		Exclude:     append([]string(nil), DefaultExclude...),
+		MinDuration: DefaultMinDuration,
+		MaxDuration: DefaultMaxDuration,
+	}
+}
+
+// Merge returns f with a per-song filter applied on top: its words are
+// added and its non-zero durations replace f's.
+func (f Filter) Merge(line *Filter) Filter {
+	if line == nil {
+		return f
+	}
+	merged := Filter{
+		Include:     append(append([]string(nil), f.Include...), line.Include...),
+		Exclude:     append(append([]string(nil), f.Exclude...), line.Exclude...),
+		MinDuration: f.MinDuration,
+		MaxDuration: f.MaxDuration,
+	}
+	if line.MinDuration > 0 {
+		merged.MinDuration = line.MinDuration
+	}
+	if line.MaxDuration > 0 {
+		merged.MaxDuration = line.MaxDuration
+	}
+	return merged
+}
+
+func containsWord(text, word string) bool {
+	word = NormalizeTitle(word)
+	if word == "" {
+		return false
+	}
+	return strings.Contains(" "+text+" ", " "+word+" ")
+}
+
+// excluded returns the Exclude words that apply to song.
+func (f Filter) excluded(song string) []string {
+	folded := NormalizeTitle(song)
+	var words []string
+	for _, word := range f.Exclude {
+		if !containsWord(folded, word) {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// Reject returns why a result should not be downloaded for song, or "" if
+// it passes the filter.
+func (f Filter) Reject(c Candidate, song string) string {
+	title := foldedTitle(c.Title)
+	for _, word := range f.excluded(song) {
+		if containsWord(title, word) {
+			return fmt.Sprintf("contains %q", word)
+		}
+	}
+	for _, word := range f.Include {
+		if !containsWord(title, word) {
+			return fmt.Sprintf("missing %q", word)
+		}
+	}
+
+	length := time.Duration(c.Duration * float64(time.Second))
+	if c.Duration > 0 && f.MinDuration > 0 && length < f.MinDuration {
+		return fmt.Sprintf("shorter than %v", f.MinDuration)
+	}
+	if c.Duration > 0 && f.MaxDuration > 0 && length > f.MaxDuration {
+		return fmt.Sprintf("longer than %v", f.MaxDuration)
+	}
+	return ""
+}
+
+// foldedTitle is like NormalizeTitle but keeps bracketed parts, where
+// words like "live" or "remix" usually are.
+func foldedTitle(title string) string {
+	title = foldDiacritics(strings.ToLower(title))
+	title = apostrophe.Replace(title)
+	return strings.Join(strings.FieldsFunc(title, func(r rune) bool {
+		return !isWordRune(r)
+	}), " ")
+}
+
+// matchFilter renders the filter as a yt-dlp --match-filter expression,
+// for downloads that take the first search hit without scoring.
+func (f Filter) matchFilter(song string) string {
+	var parts []string
+	if f.MinDuration > 0 {
+		parts = append(parts, fmt.Sprintf("duration >=? %d", int(f.MinDuration.Seconds())))
+	}
+	if f.MaxDuration > 0 {
+		parts = append(parts, fmt.Sprintf("duration <=? %d", int(f.MaxDuration.Seconds())))
+	}
+	if words := f.excluded(song); len(words) > 0 {
+		parts = append(parts, fmt.Sprintf("title !~= '(?i)\\b(%s)\\b'", wordPattern(words)))
+	}
+	for _, word := range f.Include {
+		parts = append(parts, fmt.Sprintf("title ~= '(?i)\\b%s\\b'", wordPattern([]string{word})))
+	}
+	return strings.Join(parts, " & ")
+}
+
+func wordPattern(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = strings.ReplaceAll(regexp.QuoteMeta(strings.ToLower(word)), "'", "")
+	}
+	return strings.Join(quoted, "|")
+}
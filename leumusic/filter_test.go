@@ -0,0 +1,40 @@
+package leumusic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterReject(t *testing.T) {
+	f := Filter{Exclude: []string{"live", "remix"}, Include: []string{"audio"}, MinDuration: time.Minute, MaxDuration: 10 * time.Minute}
+	tests := []struct {
+		title    string
+		duration float64
+		song     string
+		want     string
+	}{
+		{"Queen - Innuendo (Official Audio)", 391, "Innuendo", ""},
+		{"Queen - Innuendo (Live at Wembley) audio", 400, "Innuendo", `contains "live"`},
+		{"Queen - Innuendo (Live) audio", 400, "Innuendo (Live)", ""},
+		{"Queen - Innuendo", 391, "Innuendo", `missing "audio"`},
+		{"Queen - Innuendo audio", 30, "Innuendo", "shorter than 1m0s"},
+		{"Queen - Innuendo audio", 3600, "Innuendo", "longer than 10m0s"},
+		{"Queen - Innuendo audio", 0, "Innuendo", ""},
+	}
+	for _, tt := range tests {
+		if got := f.Reject(Candidate{Title: tt.title, Duration: tt.duration}, tt.song); got != tt.want {
+			t.Errorf("Reject(%q, %v) = %q, want %q", tt.title, tt.duration, got, tt.want)
+		}
+	}
+}
+
+func TestFilterMatchFilter(t *testing.T) {
+	f := Filter{Exclude: []string{"live"}, MinDuration: time.Minute, MaxDuration: 15 * time.Minute}
+	want := `duration >=? 60 & duration <=? 900 & title !~= '(?i)\b(live)\b'`
+	if got := f.matchFilter("Innuendo"); got != want {
+		t.Errorf("matchFilter = %q, want %q", got, want)
+	}
+	if got := (Filter{}).matchFilter("Innuendo"); got != "" {
+		t.Errorf("empty filter matchFilter = %q, want none", got)
+	}
+}
@@ -0,0 +1,429 @@
+package leumusic
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// IsImportFile reports whether path is a song list ImportFile reads, as
+// opposed to a songs file.
+func IsImportFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".csv", ".xml", ".m3u", ".m3u8", ".pls":
+		return true
+	}
+	return false
+}
+
+// ImportFile reads the songs of an exported song list, picked by its
+// extension: a Spotify data export (.json), a CSV playlist export (.csv),
+// an iTunes or Apple Music Library.xml (.xml), or an M3U (.m3u, .m3u8) or
+// PLS (.pls) playlist. The songs of a playlist have Playlist set to it; an
+// M3U or PLS file is a playlist named after the file. A song listed more
+// than once in the same playlist is read once.
+func ImportFile(path string) ([]DownloadTask, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var tasks []DownloadTask
+	ext := strings.ToLower(filepath.Ext(path))
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	switch ext {
+	case ".json":
+		tasks, err = ImportSpotify(file)
+	case ".csv":
+		tasks, err = ImportCSV(file)
+	case ".xml":
+		tasks, err = ImportITunes(file)
+	case ".m3u", ".m3u8":
+		tasks, err = ImportM3U(file, name)
+	case ".pls":
+		tasks, err = ImportPLS(file, name)
+	default:
+		err = fmt.Errorf("unknown song list format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	seen := make(map[string]bool, len(tasks))
+	unique := tasks[:0]
+	for _, t := range tasks {
+		if key := t.Playlist + "\x00" + manifestKey(t.Artist, t.Song, t.URL); !seen[key] {
+			seen[key] = true
+			unique = append(unique, t)
+		}
+	}
+	return unique, nil
+}
+
+// spotifyExport holds the parts of a Spotify privacy export file that list
+// songs: the liked songs of YourLibrary.json and the playlists of
+// Playlist1.json.
+type spotifyExport struct {
+	Tracks []struct {
+		Artist string `json:"artist"`
+		Album  string `json:"album"`
+		Track  string `json:"track"`
+	} `json:"tracks"`
+	Playlists []struct {
+		Name  string `json:"name"`
+		Items []struct {
+			Track *struct {
+				TrackName  string `json:"trackName"`
+				ArtistName string `json:"artistName"`
+				AlbumName  string `json:"albumName"`
+			} `json:"track"`
+			LocalTrack *struct {
+				URI string `json:"uri"`
+			} `json:"localTrack"`
+		} `json:"items"`
+	} `json:"playlists"`
+}
+
+// ImportSpotify reads a YourLibrary.json or Playlist1.json file of a
+// Spotify privacy export. The songs of a playlist have Playlist set to
+// it; liked songs are in none. Podcasts and audiobooks are left out.
+// Local files are included too, from what their URI says.
+func ImportSpotify(r io.Reader) ([]DownloadTask, error) {
+	var export spotifyExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, err
+	}
+	if export.Tracks == nil && export.Playlists == nil {
+		return nil, errors.New("not a Spotify YourLibrary.json or Playlist1.json file")
+	}
+
+	var tasks []DownloadTask
+	add := func(t DownloadTask) {
+		t.Artist, t.Song = strings.TrimSpace(t.Artist), strings.TrimSpace(t.Song)
+		if t.Artist != "" && t.Song != "" {
+			tasks = append(tasks, t)
+		}
+	}
+	for _, t := range export.Tracks {
+		add(DownloadTask{Artist: t.Artist, Song: t.Track, Album: t.Album})
+	}
+	for _, p := range export.Playlists {
+		name := strings.TrimSpace(p.Name)
+		for _, item := range p.Items {
+			var t DownloadTask
+			switch {
+			case item.Track != nil:
+				t = DownloadTask{Artist: item.Track.ArtistName, Song: item.Track.TrackName, Album: item.Track.AlbumName}
+			case item.LocalTrack != nil:
+				t = spotifyLocalTrack(item.LocalTrack.URI)
+			default:
+				continue
+			}
+			t.Playlist = name
+			add(t)
+		}
+	}
+	return tasks, nil
+}
+
+// spotifyLocalTrack reads a local file URI,
+// "spotify:local:artist:album:title:seconds", with its fields URL-encoded.
+func spotifyLocalTrack(uri string) DownloadTask {
+	fields := strings.Split(strings.TrimPrefix(uri, "spotify:local:"), ":")
+	if len(fields) != 4 {
+		return DownloadTask{}
+	}
+	for i, f := range fields {
+		if unescaped, err := url.QueryUnescape(f); err == nil {
+			fields[i] = unescaped
+		}
+	}
+	t := DownloadTask{Artist: fields[0], Album: fields[1], Song: fields[2]}
+	if seconds, err := strconv.Atoi(fields[3]); err == nil && seconds > 0 {
+		t.Duration = time.Duration(seconds) * time.Second
+	}
+	return t
+}
+
+// csvColumns are the header names CSV playlist exports use, such as
+// Exportify's "Track Name", "Artist Name(s)", "Album Name" and "Track
+// Duration (ms)", folded to lower case letters.
+var csvColumns = map[string][]string{
+	"song":     {"trackname", "track", "songname", "song", "title", "name"},
+	"artist":   {"artistnames", "artistname", "artists", "artist"},
+	"album":    {"albumname", "album"},
+	"duration": {"trackdurationms", "durationms", "trackduration", "duration", "length", "time"},
+}
+
+func csvHeader(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// ImportCSV reads a CSV playlist export with a header row, such as one
+// from Exportify. Only the track name and artist columns are required;
+// the album and duration are used when present. Semicolon-separated files
+// are read too.
+func ImportCSV(r io.Reader) ([]DownloadTask, error) {
+	br := bufio.NewReader(r)
+	first, _ := br.Peek(4096)
+	header, _, _ := strings.Cut(string(first), "\n")
+
+	cr := csv.NewReader(br)
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		cr.Comma = ';'
+	}
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	names, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for field, aliases := range csvColumns {
+		for _, alias := range aliases {
+			for i, name := range names {
+				if _, found := columns[field]; !found && csvHeader(name) == alias {
+					columns[field] = i
+				}
+			}
+		}
+	}
+	for _, field := range []string{"song", "artist"} {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("no %s column in the header", field)
+		}
+	}
+	inMillis := false
+	if i, ok := columns["duration"]; ok {
+		inMillis = strings.HasSuffix(csvHeader(names[i]), "ms")
+	}
+
+	var tasks []DownloadTask
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return tasks, nil
+		}
+		if err != nil {
+			return tasks, err
+		}
+		value := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		t := DownloadTask{Artist: firstArtist(value("artist")), Song: value("song"), Album: value("album")}
+		if t.Artist == "" || t.Song == "" {
+			continue
+		}
+		t.Duration = parseTrackDuration(value("duration"), inMillis)
+		tasks = append(tasks, t)
+	}
+}
+
+// firstArtist returns the main artist of a list such as "Daft Punk,Pharrell
+// Williams" or "Daft Punk; Pharrell Williams". A comma followed by a space
+// is part of a name, as in "Earth, Wind & Fire".
+func firstArtist(artists string) string {
+	artists, _, _ = strings.Cut(artists, ";")
+	for i := 0; i < len(artists); i++ {
+		if artists[i] == ',' && (i+1 == len(artists) || artists[i+1] != ' ') {
+			return strings.TrimSpace(artists[:i])
+		}
+	}
+	return strings.TrimSpace(artists)
+}
+
+// parseTrackDuration reads a track length written as milliseconds, seconds
+// or "m:ss". Anything else is an unknown length.
+func parseTrackDuration(value string, inMillis bool) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if minutes, seconds, ok := strings.Cut(value, ":"); ok {
+		m, err1 := strconv.Atoi(minutes)
+		s, err2 := strconv.Atoi(seconds)
+		if err1 != nil || err2 != nil || m < 0 || s < 0 {
+			return 0
+		}
+		return time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	if inMillis {
+		return time.Duration(n) * time.Millisecond
+	}
+	return time.Duration(n * float64(time.Second))
+}
+
+// ImportM3U reads an M3U or extended M3U playlist named name, unless a
+// #PLAYLIST line names it. A song's artist and title come from its
+// #EXTINF line as "artist - title", or else from its file name. A web
+// address is downloaded as is.
+func ImportM3U(r io.Reader, name string) ([]DownloadTask, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []DownloadTask
+	var title string
+	var seconds float64
+	for _, line := range strings.Split(playlistText(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#EXTM3U"):
+		case strings.HasPrefix(line, "#PLAYLIST:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "#PLAYLIST:"))
+		case strings.HasPrefix(line, "#EXTINF:"):
+			info, rest, _ := strings.Cut(strings.TrimPrefix(line, "#EXTINF:"), ",")
+			length, _, _ := strings.Cut(strings.TrimSpace(info), " ")
+			seconds, _ = strconv.ParseFloat(length, 64)
+			title = strings.TrimSpace(rest)
+		case strings.HasPrefix(line, "#"):
+		default:
+			if t, ok := playlistFileTask(line, title, seconds); ok {
+				t.Playlist = name
+				tasks = append(tasks, t)
+			}
+			title, seconds = "", 0
+		}
+	}
+	return tasks, nil
+}
+
+// ImportPLS reads a PLS playlist named name, with each entry's FileN,
+// TitleN and LengthN read as in ImportM3U.
+func ImportPLS(r io.Reader, name string) ([]DownloadTask, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	type entry struct {
+		file, title string
+		seconds     float64
+	}
+	entries := map[int]*entry{}
+	for _, line := range strings.Split(playlistText(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		field := strings.TrimRight(key, "0123456789")
+		n, err := strconv.Atoi(key[len(field):])
+		if err != nil {
+			continue
+		}
+		if entries[n] == nil {
+			entries[n] = &entry{}
+		}
+		value = strings.TrimSpace(value)
+		switch field {
+		case "file":
+			entries[n].file = value
+		case "title":
+			entries[n].title = value
+		case "length":
+			entries[n].seconds, _ = strconv.ParseFloat(value, 64)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("not a PLS playlist")
+	}
+
+	numbers := make([]int, 0, len(entries))
+	for n := range entries {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	var tasks []DownloadTask
+	for _, n := range numbers {
+		e := entries[n]
+		if t, ok := playlistFileTask(e.file, e.title, e.seconds); ok {
+			t.Playlist = name
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks, nil
+}
+
+// playlistText returns a playlist file as UTF-8 with "\n" line ends. Files
+// that aren't UTF-8 are taken to be Latin-1, as old .m3u files often are.
+func playlistText(data []byte) string {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	text := string(data)
+	if !utf8.Valid(data) {
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		text = string(runes)
+	}
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+var trackNumber = regexp.MustCompile(`^\d{1,3}(\s*[-.]\s*|\s+)`)
+
+// playlistFileTask turns a playlist entry into a task: the song named by
+// title ("artist - title"), else by the file name, with the artist taken
+// from the folder two levels up (artist/album/song) if the name doesn't
+// say. A web address with no title is downloaded as is; a title with a
+// web address pins the song to it. A length of zero or less is unknown.
+func playlistFileTask(location, title string, seconds float64) (DownloadTask, bool) {
+	var t DownloadTask
+	if seconds > 0 {
+		t.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	web := isURL(location)
+	if web {
+		t.URL = location
+	}
+
+	if artist, song, ok := strings.Cut(title, " - "); ok {
+		t.Artist, t.Song = strings.TrimSpace(artist), strings.TrimSpace(song)
+	} else if !web {
+		if u, err := url.Parse(location); err == nil && u.Scheme == "file" {
+			location = u.Path
+		}
+		location = filepath.FromSlash(strings.ReplaceAll(location, "\\", "/"))
+		base := strings.TrimSuffix(filepath.Base(location), filepath.Ext(location))
+		base = trackNumber.ReplaceAllString(base, "")
+		if artist, song, ok := strings.Cut(base, " - "); ok {
+			t.Artist, t.Song = strings.TrimSpace(artist), strings.TrimSpace(song)
+		} else if album := filepath.Dir(location); album != "." && filepath.Dir(album) != "." {
+			t.Artist, t.Song = filepath.Base(filepath.Dir(album)), strings.TrimSpace(base)
+		}
+	}
+
+	if t.Artist == "" || t.Song == "" {
+		t.Artist, t.Song = "", ""
+		return t, t.URL != ""
+	}
+	return t, true
+}
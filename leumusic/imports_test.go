@@ -0,0 +1,237 @@
+package leumusic
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImportSpotifyLibrary(t *testing.T) {
+	const library = `{
+		"tracks": [
+			{"artist": "Queen", "album": "A Night at the Opera", "track": "Bohemian Rhapsody", "uri": "spotify:track:1"},
+			{"artist": "", "album": "", "track": "No Artist", "uri": "spotify:track:2"}
+		],
+		"albums": [], "shows": [], "episodes": []
+	}`
+	got, err := ImportSpotify(strings.NewReader(library))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DownloadTask{{Artist: "Queen", Song: "Bohemian Rhapsody", Album: "A Night at the Opera"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestImportSpotifyPlaylists(t *testing.T) {
+	const playlists = `{"playlists": [
+		{"name": "Road Trip", "items": [
+			{"track": {"trackName": "Halo", "artistName": "Beyoncé", "albumName": "I Am... Sasha Fierce"}, "episode": null},
+			{"track": null, "episode": {"episodeName": "A podcast"}},
+			{"track": null, "localTrack": {"uri": "spotify:local:Daft+Punk:Discovery:One+More+Time:320"}}
+		]},
+		{"name": "Gym", "items": [
+			{"track": {"trackName": "Halo", "artistName": "Beyoncé", "albumName": "I Am... Sasha Fierce"}}
+		]}
+	]}`
+	got, err := ImportSpotify(strings.NewReader(playlists))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DownloadTask{
+		{Artist: "Beyoncé", Song: "Halo", Album: "I Am... Sasha Fierce", Playlist: "Road Trip"},
+		{Artist: "Daft Punk", Song: "One More Time", Album: "Discovery", Playlist: "Road Trip", Duration: 320 * time.Second},
+		{Artist: "Beyoncé", Song: "Halo", Album: "I Am... Sasha Fierce", Playlist: "Gym"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestImportSpotifyNotExport(t *testing.T) {
+	if _, err := ImportSpotify(strings.NewReader(`{"something": "else"}`)); err == nil {
+		t.Error("want an error for a JSON file that isn't a Spotify export")
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want []DownloadTask
+	}{
+		{
+			name: "exportify",
+			csv: "Track URI,Track Name,Artist Name(s),Album Name,Track Duration (ms)\n" +
+				"spotify:track:1,Get Lucky,\"Daft Punk,Pharrell Williams\",Random Access Memories,369626\n" +
+				"spotify:track:2,September,\"Earth, Wind & Fire\",The Best Of,215000\n",
+			want: []DownloadTask{
+				{Artist: "Daft Punk", Song: "Get Lucky", Album: "Random Access Memories", Duration: 369626 * time.Millisecond},
+				{Artist: "Earth, Wind & Fire", Song: "September", Album: "The Best Of", Duration: 215 * time.Second},
+			},
+		},
+		{
+			name: "semicolons and m:ss",
+			csv:  "Title;Artist;Length\nYellow;Coldplay;4:26\nNo artist;;3:00\n",
+			want: []DownloadTask{{Artist: "Coldplay", Song: "Yellow", Duration: 4*time.Minute + 26*time.Second}},
+		},
+		{
+			name: "seconds",
+			csv:  "artist,song,duration\nQueen,Bohemian Rhapsody,354.5\nQueen,Innuendo,unknown\n",
+			want: []DownloadTask{
+				{Artist: "Queen", Song: "Bohemian Rhapsody", Duration: 354500 * time.Millisecond},
+				{Artist: "Queen", Song: "Innuendo"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ImportCSV(strings.NewReader(tt.csv))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportCSVMissingColumn(t *testing.T) {
+	if _, err := ImportCSV(strings.NewReader("Track Name,Album\nYellow,Parachutes\n")); err == nil {
+		t.Error("want an error for a CSV file without an artist column")
+	}
+}
+
+func TestImportFileDeduplicatesPerPlaylist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "songs.csv")
+	data := "artist,song\nQueen,Bohemian Rhapsody\nqueen,bohemian rhapsody\nABBA,Waterloo\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ImportFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DownloadTask{{Artist: "Queen", Song: "Bohemian Rhapsody"}, {Artist: "ABBA", Song: "Waterloo"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestImportM3U(t *testing.T) {
+	const m3u = "\xef\xbb\xbf#EXTM3U\r\n" +
+		"#PLAYLIST:Road Trip\r\n" +
+		"#EXTINF:354,Queen - Bohemian Rhapsody\r\n" +
+		"C:\\Music\\Queen\\A Night at the Opera\\11 Bohemian Rhapsody.mp3\r\n" +
+		"Music/Coldplay/Parachutes/05 - Yellow.mp3\r\n" +
+		"file:///home/me/Music/ABBA - Waterloo.flac\r\n" +
+		"#EXTINF:-1,\r\n" +
+		"https://www.youtube.com/watch?v=abc\r\n" +
+		"#EXTINF:200,Daft Punk - One More Time\r\n" +
+		"https://www.youtube.com/watch?v=def\r\n" +
+		"lonely.mp3\r\n"
+	got, err := ImportM3U(strings.NewReader(m3u), "file name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DownloadTask{
+		{Artist: "Queen", Song: "Bohemian Rhapsody", Playlist: "Road Trip", Duration: 354 * time.Second},
+		{Artist: "Coldplay", Song: "Yellow", Playlist: "Road Trip"},
+		{Artist: "ABBA", Song: "Waterloo", Playlist: "Road Trip"},
+		{URL: "https://www.youtube.com/watch?v=abc", Playlist: "Road Trip"},
+		{Artist: "Daft Punk", Song: "One More Time", URL: "https://www.youtube.com/watch?v=def", Playlist: "Road Trip", Duration: 200 * time.Second},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestImportM3ULatin1(t *testing.T) {
+	got, err := ImportM3U(strings.NewReader("#EXTINF:0,Beyonc\xe9 - Halo\nhalo.mp3\n"), "Mix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DownloadTask{{Artist: "Beyoncé", Song: "Halo", Playlist: "Mix"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestImportPLS(t *testing.T) {
+	const pls = "[playlist]\n" +
+		"File2=Music/Coldplay/Parachutes/Yellow.mp3\n" +
+		"Length2=266\n" +
+		"File1=http://radio.example.com/stream\n" +
+		"Title1=Queen - Innuendo\n" +
+		"Length1=-1\n" +
+		"NumberOfEntries=2\n" +
+		"Version=2\n"
+	got, err := ImportPLS(strings.NewReader(pls), "Radio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DownloadTask{
+		{Artist: "Queen", Song: "Innuendo", URL: "http://radio.example.com/stream", Playlist: "Radio"},
+		{Artist: "Coldplay", Song: "Yellow", Playlist: "Radio", Duration: 266 * time.Second},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+	if _, err := ImportPLS(strings.NewReader("just text\n"), "x"); err == nil {
+		t.Error("want an error for a file that isn't a PLS playlist")
+	}
+}
+
+func TestImportITunes(t *testing.T) {
+	const library = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Major Version</key><integer>1</integer>
+	<key>Tracks</key>
+	<dict>
+		<key>10</key>
+		<dict><key>Track ID</key><integer>10</integer><key>Name</key><string>Yellow</string><key>Artist</key><string>Coldplay</string><key>Album</key><string>Parachutes</string><key>Total Time</key><integer>266000</integer></dict>
+		<key>11</key>
+		<dict><key>Track ID</key><integer>11</integer><key>Name</key><string>Halo</string><key>Album Artist</key><string>Beyoncé</string></dict>
+		<key>12</key>
+		<dict><key>Track ID</key><integer>12</integer><key>Name</key><string>Some Episode</string><key>Artist</key><string>Host</string><key>Podcast</key><true/></dict>
+		<key>13</key>
+		<dict><key>Track ID</key><integer>13</integer><key>Name</key><string>Waterloo</string><key>Artist</key><string>ABBA</string></dict>
+	</dict>
+	<key>Playlists</key>
+	<array>
+		<dict><key>Name</key><string>Library</string><key>Master</key><true/>
+			<key>Playlist Items</key><array><dict><key>Track ID</key><integer>10</integer></dict></array></dict>
+		<dict><key>Name</key><string>Music</string><key>Distinguished Kind</key><integer>4</integer>
+			<key>Playlist Items</key><array><dict><key>Track ID</key><integer>10</integer></dict></array></dict>
+		<dict><key>Name</key><string>Favourites</string>
+			<key>Playlist Items</key><array>
+				<dict><key>Track ID</key><integer>11</integer></dict>
+				<dict><key>Track ID</key><integer>12</integer></dict>
+				<dict><key>Track ID</key><integer>10</integer></dict>
+			</array></dict>
+	</array>
+</dict>
+</plist>`
+	got, err := ImportITunes(strings.NewReader(library))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DownloadTask{
+		{Artist: "Beyoncé", Song: "Halo", Playlist: "Favourites"},
+		{Artist: "Coldplay", Song: "Yellow", Album: "Parachutes", Playlist: "Favourites", Duration: 266 * time.Second},
+		{Artist: "ABBA", Song: "Waterloo"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+	if _, err := ImportITunes(strings.NewReader(`<plist><array/></plist>`)); err == nil {
+		t.Error("want an error for a property list that isn't a library")
+	}
+}
@@ -0,0 +1,183 @@
+package leumusic
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportITunes reads the Library.xml that iTunes and Apple Music export.
+// The songs of each playlist come first, in playlist order and with
+// Playlist set to it; then the songs that are in no playlist. Built-in
+// playlists such as "Music" or "Purchased" and folders are not playlists
+// here, and videos, podcasts and audiobooks are left out.
+func ImportITunes(r io.Reader) ([]DownloadTask, error) {
+	root, err := decodePlist(r)
+	if err != nil {
+		return nil, err
+	}
+	library, _ := root.(map[string]any)
+	tracks, _ := library["Tracks"].(map[string]any)
+	if tracks == nil {
+		return nil, errors.New("not an iTunes or Apple Music Library.xml file")
+	}
+
+	var tasks []DownloadTask
+	listed := map[string]bool{}
+	playlists, _ := library["Playlists"].([]any)
+	for _, p := range playlists {
+		playlist, _ := p.(map[string]any)
+		if !itunesUserPlaylist(playlist) {
+			continue
+		}
+		name, _ := playlist["Name"].(string)
+		items, _ := playlist["Playlist Items"].([]any)
+		for _, item := range items {
+			entry, _ := item.(map[string]any)
+			id, _ := entry["Track ID"].(int64)
+			key := strconv.FormatInt(id, 10)
+			track, _ := tracks[key].(map[string]any)
+			if t, ok := itunesTrack(track); ok {
+				t.Playlist = name
+				tasks = append(tasks, t)
+				listed[key] = true
+			}
+		}
+	}
+
+	ids := make([]int64, 0, len(tracks))
+	for key := range tracks {
+		if id, err := strconv.ParseInt(key, 10, 64); err == nil && !listed[key] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		track, _ := tracks[strconv.FormatInt(id, 10)].(map[string]any)
+		if t, ok := itunesTrack(track); ok {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks, nil
+}
+
+// itunesUserPlaylist reports whether p is a playlist someone made, rather
+// than the whole library, a built-in list or a folder.
+func itunesUserPlaylist(p map[string]any) bool {
+	for _, flag := range []string{"Master", "Folder"} {
+		if on, _ := p[flag].(bool); on {
+			return false
+		}
+	}
+	if visible, ok := p["Visible"].(bool); ok && !visible {
+		return false
+	}
+	_, builtIn := p["Distinguished Kind"]
+	return !builtIn && p["Name"] != nil
+}
+
+// itunesTrack turns a track of the library into a task, if it is a song.
+func itunesTrack(track map[string]any) (DownloadTask, bool) {
+	for _, flag := range []string{"Podcast", "Movie", "TV Show", "Has Video", "Music Video"} {
+		if on, _ := track[flag].(bool); on {
+			return DownloadTask{}, false
+		}
+	}
+	if kind, _ := track["Kind"].(string); strings.Contains(strings.ToLower(kind), "audiobook") {
+		return DownloadTask{}, false
+	}
+
+	var t DownloadTask
+	t.Song, _ = track["Name"].(string)
+	t.Artist, _ = track["Artist"].(string)
+	if t.Artist == "" {
+		t.Artist, _ = track["Album Artist"].(string)
+	}
+	t.Album, _ = track["Album"].(string)
+	if ms, _ := track["Total Time"].(int64); ms > 0 {
+		t.Duration = time.Duration(ms) * time.Millisecond
+	}
+	t.Artist, t.Song = strings.TrimSpace(t.Artist), strings.TrimSpace(t.Song)
+	return t, t.Artist != "" && t.Song != ""
+}
+
+// decodePlist reads an XML property list into maps, slices, strings,
+// int64s, float64s and bools.
+func decodePlist(r io.Reader) (any, error) {
+	d := xml.NewDecoder(r)
+	inPlist := false
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = errors.New("no property list found")
+			}
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		switch {
+		case !ok:
+		case start.Name.Local == "plist":
+			inPlist = true
+		case inPlist:
+			return plistValue(d, start)
+		}
+	}
+}
+
+func plistValue(d *xml.Decoder, start xml.StartElement) (any, error) {
+	switch start.Name.Local {
+	case "dict", "array":
+		dict := map[string]any{}
+		var array []any
+		var key string
+		for {
+			tok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if t.Name.Local == "key" {
+					if err := d.DecodeElement(&key, &t); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				v, err := plistValue(d, t)
+				if err != nil {
+					return nil, err
+				}
+				if start.Name.Local == "dict" {
+					dict[key] = v
+				} else {
+					array = append(array, v)
+				}
+			case xml.EndElement:
+				if start.Name.Local == "dict" {
+					return dict, nil
+				}
+				return array, nil
+			}
+		}
+	case "true", "false":
+		return start.Name.Local == "true", d.Skip()
+	}
+
+	var text string
+	if err := d.DecodeElement(&text, &start); err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+	switch start.Name.Local {
+	case "integer":
+		return strconv.ParseInt(text, 10, 64)
+	case "real":
+		return strconv.ParseFloat(text, 64)
+	}
+	return text, nil
+}
@@ -0,0 +1,175 @@
+package leumusic
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TaskState is where a task of a session stands.
+type TaskState string
+
+const (
+	TaskQueued  TaskState = "queued"
+	TaskRunning TaskState = "running"
+	TaskDone    TaskState = "done"
+	TaskFailed  TaskState = "failed"
+)
+
+// JournalEntry is the last known state of one task of a session.
+type JournalEntry struct {
+	Index int `json:"index"`
+	DownloadTask
+	State TaskState `json:"state"`
+	Error string    `json:"error,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// Journal records the tasks of a download session as they move along, so
+// a session that was interrupted or crashed can be resumed. It is a JSON
+// Lines file: Begin writes every task as queued, and each state change
+// appends a line that overrides the task's earlier ones.
+type Journal struct {
+	path    string
+	mu      sync.Mutex
+	entries map[int]JournalEntry
+}
+
+// OpenJournal loads the journal at path. A missing file is an empty
+// journal.
+func OpenJournal(path string) (*Journal, error) {
+	jr := &Journal{path: path, entries: map[int]JournalEntry{}}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return jr, nil
+	}
+	if err != nil {
+		return jr, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e JournalEntry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			// Blank, or cut short by a crash.
+			continue
+		}
+		if old, ok := jr.entries[e.Index]; ok && e.Artist == "" && e.URL == "" {
+			old.State, old.Error, old.Time = e.State, e.Error, e.Time
+			e = old
+		}
+		jr.entries[e.Index] = e
+	}
+	return jr, scanner.Err()
+}
+
+// Path returns the file backing the journal.
+func (jr *Journal) Path() string {
+	return jr.path
+}
+
+// Begin starts a new session with tasks, all queued, replacing whatever
+// the journal held.
+func (jr *Journal) Begin(tasks []DownloadTask) error {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	now := time.Now().UTC()
+	jr.entries = make(map[int]JournalEntry, len(tasks))
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	for i, t := range tasks {
+		e := JournalEntry{Index: i, DownloadTask: t, State: TaskQueued, Time: now}
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+		jr.entries[i] = e
+	}
+
+	if dir := filepath.Dir(jr.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(jr.path, []byte(buf.String()), 0644)
+}
+
+// Set records the new state of the task at index, with the reason it
+// failed if there is one.
+func (jr *Journal) Set(index int, state TaskState, reason error) error {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	e, ok := jr.entries[index]
+	if !ok {
+		return fmt.Errorf("journal has no task %d", index)
+	}
+	change := JournalEntry{Index: index, State: state, Time: time.Now().UTC()}
+	if reason != nil {
+		change.Error = reason.Error()
+	}
+
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(jr.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	e.State, e.Error, e.Time = change.State, change.Error, change.Time
+	jr.entries[index] = e
+	return nil
+}
+
+// Entries returns every task of the session in the order it was begun.
+func (jr *Journal) Entries() []JournalEntry {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	entries := make([]JournalEntry, 0, len(jr.entries))
+	for _, e := range jr.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(a, b int) bool { return entries[a].Index < entries[b].Index })
+	return entries
+}
+
+// Pending returns the tasks that were queued or running when the session
+// stopped, in order.
+func (jr *Journal) Pending() []DownloadTask {
+	var tasks []DownloadTask
+	for _, e := range jr.Entries() {
+		if e.State == TaskQueued || e.State == TaskRunning {
+			tasks = append(tasks, e.DownloadTask)
+		}
+	}
+	return tasks
+}
+
+// Clear ends the session and removes the file.
+func (jr *Journal) Clear() error {
+	jr.mu.Lock()
+	defer jr.mu.Unlock()
+
+	jr.entries = map[int]JournalEntry{}
+	if err := os.Remove(jr.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package leumusic
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJournalResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	jr, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks := []DownloadTask{
+		{Artist: "Queen", Song: "Innuendo", Format: FormatFLAC, Album: "Innuendo"},
+		{Artist: "ABBA", Song: "Waterloo", Playlist: "Road Trip", Duration: 165 * time.Second},
+		{URL: "https://www.youtube.com/watch?v=abc", NoCookies: true, Filter: &Filter{Exclude: []string{"live"}}},
+	}
+	if err := jr.Begin(tasks); err != nil {
+		t.Fatal(err)
+	}
+	if err := jr.Set(0, TaskDone, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := jr.Set(2, TaskFailed, errors.New("unavailable")); err != nil {
+		t.Fatal(err)
+	}
+	if err := jr.Set(1, TaskRunning, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	jr, err = OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := jr.Pending(), tasks[1:2]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Pending() = %+v, want %+v", got, want)
+	}
+	entries := jr.Entries()
+	if len(entries) != 3 || entries[2].State != TaskFailed || entries[2].Error != "unavailable" || !reflect.DeepEqual(entries[2].DownloadTask, tasks[2]) {
+		t.Errorf("Entries() = %+v", entries)
+	}
+}
+
+func TestJournalReadsSessionLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	lines := `{"index":0,"artist":"Queen","song":"Innuendo","format":"flac","no_cookies":true,"playlist":"Mix","duration":391000000000,"state":"queued","time":"2026-01-02T03:04:05Z"}
+{"index":1,"url":"https://www.youtube.com/watch?v=abc","state":"queued","time":"2026-01-02T03:04:05Z"}
+{"index":0,"state":"running","time":"2026-01-02T03:04:06Z"}
+{"index":1,"state":"done","time":"2026-01-02T03:04:07Z"}
+{"index":1,"sta`
+	if err := os.WriteFile(path, []byte(lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+	jr, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DownloadTask{{Artist: "Queen", Song: "Innuendo", Format: FormatFLAC, NoCookies: true, Playlist: "Mix", Duration: 391 * time.Second}}
+	if got := jr.Pending(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Pending() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,347 @@
+package leumusic
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Ledger remembers which songs were already downloaded. Has should treat
+// differently written titles of the same song as equal.
+// Implementations must be safe for concurrent use.
+type Ledger interface {
+	Has(artist, song string) bool
+	Add(rec Record) error
+}
+
+// Record describes one downloaded song.
+type Record struct {
+	Artist    string `json:"artist"`
+	Song      string `json:"song"`
+	VideoID   string `json:"video_id,omitempty"`
+	SourceURL string `json:"source_url,omitempty"`
+	// Provider and Rank tell which search result was downloaded, after any
+	// fallbacks.
+	Provider  string    `json:"provider,omitempty"`
+	Rank      int       `json:"rank,omitempty"`
+	FilePath  string    `json:"file_path,omitempty"`
+	Format    string    `json:"format,omitempty"`
+	Quality   string    `json:"quality,omitempty"`
+	Size      int64     `json:"size,omitempty"`
+	Duration  float64   `json:"duration,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Key returns the "artist - song" form of the record.
+func (r Record) Key() string {
+	return DownloadTask{Artist: r.Artist, Song: r.Song}.Key()
+}
+
+// Store is a Ledger kept in a JSON Lines file: every Add appends one
+// record, a later line for the same song replaces the earlier one, and
+// Remove appends a line that drops it. Superseded lines are dropped when
+// the store is opened, along with a line a crash cut short. Being a plain
+// file, it needs no database next to the program and can be read and
+// fixed by hand; SQLite or an embedded key-value store would give neither.
+// The price is that the whole library is held in memory, compacting
+// rewrites the whole file, only one process may use it at a time, and a
+// crash mid-write loses the record being written. Lookups match songs
+// with a Matcher, so case, accents and title decorations don't count.
+type Store struct {
+	path       string
+	mu         sync.RWMutex
+	records    map[string]Record
+	order      []string
+	normalized map[string]string
+	// artists holds the keys of the records of each normalized artist, so
+	// a fuzzy lookup only compares the songs of artists that match.
+	artists map[string][]string
+	matcher Matcher
+}
+
+// removal is the line Remove appends for a song that was forgotten.
+type removal struct {
+	Artist  string `json:"artist"`
+	Song    string `json:"song"`
+	Removed bool   `json:"removed"`
+}
+
+// OpenStore loads the store at path. A missing file is not an error.
+func OpenStore(path string) (*Store, error) {
+	s := &Store{path: path, records: map[string]Record{}, normalized: map[string]string{}, artists: map[string][]string{}}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+
+	lines := 0
+	var skipped []error
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		lines++
+
+		var rec struct {
+			Record
+			Removed bool `json:"removed"`
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			// Cut short by a crash, most likely. The rest still loads,
+			// and compacting drops the line.
+			skipped = append(skipped, fmt.Errorf("%s:%d: skipped unreadable line: %w", path, lines, err))
+			continue
+		}
+		if rec.Removed {
+			s.drop(rec.Key())
+		} else {
+			s.put(rec.Record)
+		}
+	}
+	file.Close()
+	if err := scanner.Err(); err != nil {
+		return s, err
+	}
+
+	if lines > len(s.records) {
+		if err := s.compact(); err != nil {
+			return s, err
+		}
+	}
+	return s, errors.Join(skipped...)
+}
+
+// Path returns the file backing the store.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// SetMatcher changes how lookups decide two songs are the same.
+func (s *Store) SetMatcher(m Matcher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.matcher = m
+}
+
+func normalizedKey(artist, song string) string {
+	return NormalizeTitle(artist) + "\x00" + NormalizeTitle(song)
+}
+
+func (s *Store) put(rec Record) {
+	key := rec.Key()
+	if _, ok := s.records[key]; !ok {
+		s.order = append(s.order, key)
+		s.normalized[normalizedKey(rec.Artist, rec.Song)] = key
+		artist := NormalizeTitle(rec.Artist)
+		s.artists[artist] = append(s.artists[artist], key)
+	}
+	s.records[key] = rec
+}
+
+func (s *Store) drop(key string) {
+	rec, ok := s.records[key]
+	if !ok {
+		return
+	}
+	delete(s.records, key)
+	delete(s.normalized, normalizedKey(rec.Artist, rec.Song))
+	artist := NormalizeTitle(rec.Artist)
+	s.artists[artist] = slices.DeleteFunc(s.artists[artist], func(k string) bool { return k == key })
+	if len(s.artists[artist]) == 0 {
+		delete(s.artists, artist)
+	}
+	s.order = slices.DeleteFunc(s.order, func(k string) bool { return k == key })
+}
+
+func (s *Store) compact() error {
+	tmp := s.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, key := range s.order {
+		if err := enc.Encode(s.records[key]); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Has reports whether the song is recorded.
+func (s *Store) Has(artist, song string) bool {
+	_, ok := s.Get(artist, song)
+	return ok
+}
+
+// Get returns the record for a song: an exact match first, then the
+// closest record the matcher accepts.
+func (s *Store) Get(artist, song string) (Record, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if rec, ok := s.records[DownloadTask{Artist: artist, Song: song}.Key()]; ok {
+		return rec, true
+	}
+	if key, ok := s.normalized[normalizedKey(artist, song)]; ok {
+		return s.records[key], true
+	}
+
+	// The same comparison as Matcher.Same, but only the songs of artists
+	// that are close enough are looked at.
+	artist, song = NormalizeTitle(artist), NormalizeTitle(song)
+	threshold := s.matcher.threshold()
+	var best Record
+	bestScore := 0.0
+	for name, keys := range s.artists {
+		artistScore := similarity(artist, name)
+		if artistScore < threshold {
+			continue
+		}
+		for _, key := range keys {
+			rec := s.records[key]
+			score := min(artistScore, similarity(song, NormalizeTitle(rec.Song)))
+			if score >= threshold && score > bestScore {
+				best, bestScore = rec, score
+			}
+		}
+	}
+	return best, bestScore > 0
+}
+
+// Add inserts or updates a record and appends it to the file. CreatedAt is
+// kept from an existing record; UpdatedAt is always set to now.
+func (s *Store) Add(rec Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	if old, ok := s.records[rec.Key()]; ok && !old.CreatedAt.IsZero() {
+		rec.CreatedAt = old.CreatedAt
+	}
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = now
+	}
+	rec.UpdatedAt = now
+
+	if err := s.append(rec); err != nil {
+		return err
+	}
+	s.put(rec)
+	return nil
+}
+
+// Remove forgets a song, as when its file was deleted, by appending a
+// line that drops it. A song that isn't recorded is not an error.
+func (s *Store) Remove(artist, song string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.normalized[normalizedKey(artist, song)]
+	if !ok {
+		return nil
+	}
+	rec := s.records[key]
+	if err := s.append(removal{Artist: rec.Artist, Song: rec.Song, Removed: true}); err != nil {
+		return err
+	}
+	s.drop(key)
+	return nil
+}
+
+// append writes v as one more line of the file, after ending a line that
+// a crash left unfinished so v isn't glued onto it.
+func (s *Store) append(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(s.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	line := append(data, '\n')
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			line = append([]byte{'\n'}, line...)
+		}
+	}
+	_, err = file.Write(line)
+	return err
+}
+
+// Records returns every record in insertion order.
+func (s *Store) Records() []Record {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]Record, 0, len(s.order))
+	for _, key := range s.order {
+		records = append(records, s.records[key])
+	}
+	return records
+}
+
+// ImportText adds every "artist - song" line of a legacy downloaded.txt
+// file that is not recorded yet, and returns how many were added.
+func (s *Store) ImportText(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var importedAt time.Time
+	if info, err := file.Stat(); err == nil {
+		importedAt = info.ModTime().UTC()
+	}
+
+	imported := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		task := ParseLine(strings.TrimSpace(scanner.Text()))
+		if task == nil || task.Song == "" || s.Has(task.Artist, task.Song) {
+			continue
+		}
+		rec := Record{Artist: task.Artist, Song: task.Song, Format: "mp3", CreatedAt: importedAt}
+		if err := s.Add(rec); err != nil {
+			return imported, err
+		}
+		imported++
+	}
+	return imported, scanner.Err()
+}
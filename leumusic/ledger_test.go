@@ -0,0 +1,137 @@
+package leumusic
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStoreGet(t *testing.T) {
+	s, err := OpenStore(filepath.Join(t.TempDir(), "library.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range []Record{
+		{Artist: "Queen", Song: "Bohemian Rhapsody"},
+		{Artist: "Beyoncé", Song: "Halo"},
+		{Artist: "Beethoven", Song: "Symphony No. 5"},
+	} {
+		if err := s.Add(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		artist, song string
+		want         string
+	}{
+		{"Queen", "Bohemian Rhapsody", "Queen - Bohemian Rhapsody"},
+		{"QUEEN", "bohemian rhapsody", "Queen - Bohemian Rhapsody"},
+		{"Queen", "Bohemian Rapsody (Official Video)", "Queen - Bohemian Rhapsody"},
+		{"Beyonce", "Halo", "Beyoncé - Halo"},
+		{"Beethoven", "Symphony No. 9", ""},
+		{"ABBA", "Bohemian Rhapsody", ""},
+	}
+	for _, tt := range tests {
+		var got string
+		if rec, ok := s.Get(tt.artist, tt.song); ok {
+			got = rec.Key()
+		}
+		if got != tt.want {
+			t.Errorf("Get(%q, %q) = %q, want %q", tt.artist, tt.song, got, tt.want)
+		}
+	}
+}
+
+func TestStoreRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "library.jsonl")
+	s, err := OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range []Record{{Artist: "Queen", Song: "Innuendo"}, {Artist: "ABBA", Song: "Waterloo"}} {
+		if err := s.Add(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Remove("queen", "innuendo"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Has("Queen", "Innuendo") {
+		t.Error("Queen - Innuendo is still recorded after Remove")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 3 {
+		t.Errorf("file has %d lines after Remove, want the 2 records and 1 removal", lines)
+	}
+
+	s, err = OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Has("Queen", "Innuendo") || !s.Has("ABBA", "Waterloo") {
+		t.Errorf("reopened store has %v, want only ABBA - Waterloo", s.Records())
+	}
+	if data, _ := os.ReadFile(path); strings.Count(string(data), "\n") != 1 {
+		t.Errorf("reopening didn't compact the file:\n%s", data)
+	}
+}
+
+func TestStoreTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "library.jsonl")
+	torn := `{"artist":"Queen","song":"Innuendo"}` + "\n" + `{"artist":"ABBA","so`
+	if err := os.WriteFile(path, []byte(torn), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := OpenStore(path)
+	if err == nil || !strings.Contains(err.Error(), path+":2:") {
+		t.Errorf("OpenStore error = %v, want line 2 reported", err)
+	}
+	if err := s.Add(Record{Artist: "Oasis", Song: "Wonderwall"}); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.Records()); n != 2 {
+		t.Errorf("reopened store has %d records, want 2", n)
+	}
+}
+
+func TestStoreAppendEndsLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "library.jsonl")
+	s, err := OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Left unfinished by a crash after the store was opened.
+	if err := os.WriteFile(path, []byte(`{"artist":"ABBA","so`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range []Record{{Artist: "Queen", Song: "Innuendo"}, {Artist: "Oasis", Song: "Wonderwall"}} {
+		if err := s.Add(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err = OpenStore(path)
+	if err == nil || !strings.Contains(err.Error(), path+":1:") {
+		t.Errorf("OpenStore error = %v, want only line 1 reported", err)
+	}
+	for _, rec := range []Record{{Artist: "Queen", Song: "Innuendo"}, {Artist: "Oasis", Song: "Wonderwall"}} {
+		if _, ok := s.Get(rec.Artist, rec.Song); !ok {
+			t.Errorf("reopened store lost %q", rec.Key())
+		}
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "ABBA") {
+		t.Errorf("compacted file still holds the torn line:\n%s", data)
+	}
+}
@@ -0,0 +1,130 @@
+package leumusic
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ManifestFile is the manifest kept in each synced playlist's folder.
+const ManifestFile = ".leumusic-playlist.json"
+
+// Manifest is what a synced playlist held the last time it was synced:
+// the songs that were downloaded or already on disk, in playlist order.
+// It also keeps the playlist line, named after the playlist, so the next
+// sync downloads new songs the same way.
+type Manifest struct {
+	DownloadTask
+	Songs    []ManifestSong `json:"songs"`
+	SyncedAt time.Time      `json:"synced_at"`
+}
+
+// ManifestSong is one song of a synced playlist and where it was saved.
+type ManifestSong struct {
+	DownloadTask
+	FilePath string `json:"file_path,omitempty"`
+}
+
+// Task returns the playlist as a task, named after the manifest so a
+// playlist renamed upstream keeps its folder.
+func (m Manifest) Task() DownloadTask {
+	return m.DownloadTask
+}
+
+func manifestKey(artist, song, url string) string {
+	if url != "" {
+		return url
+	}
+	return normalizedKey(artist, song)
+}
+
+// Diff compares the songs the playlist lists now with the manifest. added
+// are the songs it doesn't have yet and removed the ones no longer listed,
+// both in playlist order.
+func (m Manifest) Diff(songs []DownloadTask) (added []DownloadTask, removed []ManifestSong) {
+	listed := make(map[string]bool, len(songs))
+	for _, t := range songs {
+		listed[manifestKey(t.Artist, t.Song, t.URL)] = true
+	}
+	synced := make(map[string]bool, len(m.Songs))
+	for _, s := range m.Songs {
+		key := manifestKey(s.Artist, s.Song, s.URL)
+		synced[key] = true
+		if !listed[key] {
+			removed = append(removed, s)
+		}
+	}
+	for _, t := range songs {
+		if !synced[manifestKey(t.Artist, t.Song, t.URL)] {
+			added = append(added, t)
+		}
+	}
+	return added, removed
+}
+
+// Update sets the manifest to playlist, with the songs of songs that are
+// synced now: those it had, and those filePath finds on disk. Songs that
+// failed are left out so the next sync tries them again.
+func (m *Manifest) Update(playlist DownloadTask, info PlaylistInfo, songs []DownloadTask, filePath func(DownloadTask) string) {
+	old := make(map[string]ManifestSong, len(m.Songs))
+	for _, s := range m.Songs {
+		old[manifestKey(s.Artist, s.Song, s.URL)] = s
+	}
+
+	m.DownloadTask = playlist
+	m.URL, m.Playlist = info.URL, info.Name
+	m.Songs = m.Songs[:0:0]
+	for _, t := range songs {
+		s, ok := old[manifestKey(t.Artist, t.Song, t.URL)]
+		if path := filePath(t); path != "" {
+			s, ok = ManifestSong{DownloadTask: t, FilePath: path}, true
+		}
+		if ok {
+			m.Songs = append(m.Songs, s)
+		}
+	}
+	m.SyncedAt = time.Now().UTC()
+}
+
+// ReadManifest loads the manifest in folder. A playlist that was never
+// synced has an empty one.
+func ReadManifest(folder string) (Manifest, error) {
+	var m Manifest
+	data, err := os.ReadFile(filepath.Join(folder, ManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return m, err
+	}
+	return m, json.Unmarshal(data, &m)
+}
+
+// Save writes the manifest into folder.
+func (m Manifest) Save(folder string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(folder, ManifestFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// SyncedFolders returns the folders under dir that hold a manifest.
+func SyncedFolders(dir string) []string {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*", ManifestFile))
+	folders := make([]string, 0, len(paths))
+	for _, path := range paths {
+		folders = append(folders, filepath.Dir(path))
+	}
+	return folders
+}
@@ -0,0 +1,47 @@
+package leumusic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestManifestSync(t *testing.T) {
+	playlist := DownloadTask{URL: "https://www.youtube.com/playlist?list=PL1", Playlist: "Trip", Format: FormatFLAC}
+	info := PlaylistInfo{Name: "Road Trip", URL: "https://www.youtube.com/playlist?list=PL1"}
+	a := DownloadTask{Artist: "Queen", Song: "Innuendo", URL: "https://www.youtube.com/watch?v=a", Playlist: "Trip"}
+	b := DownloadTask{Artist: "ABBA", Song: "Waterloo", URL: "https://www.youtube.com/watch?v=b", Playlist: "Trip"}
+	c := DownloadTask{Artist: "Oasis", Song: "Wonderwall", URL: "https://www.youtube.com/watch?v=c", Playlist: "Trip"}
+
+	var m Manifest
+	if added, removed := m.Diff([]DownloadTask{a, b}); len(added) != 2 || len(removed) != 0 {
+		t.Fatalf("first Diff = %v, %v; want both songs added", added, removed)
+	}
+	onDisk := map[string]string{a.URL: "Trip/a.flac"}
+	m.Update(playlist, info, []DownloadTask{a, b}, func(t DownloadTask) string { return onDisk[t.URL] })
+	if want := (DownloadTask{URL: info.URL, Playlist: "Road Trip", Format: FormatFLAC}); m.Task() != want {
+		t.Errorf("Task() = %+v, want %+v", m.Task(), want)
+	}
+	if want := []ManifestSong{{DownloadTask: a, FilePath: "Trip/a.flac"}}; !reflect.DeepEqual(m.Songs, want) {
+		t.Errorf("Songs = %+v, want only the one on disk: %+v", m.Songs, want)
+	}
+
+	added, removed := m.Diff([]DownloadTask{b, c})
+	if want := []DownloadTask{b, c}; !reflect.DeepEqual(added, want) {
+		t.Errorf("added = %+v, want %+v", added, want)
+	}
+	if want := []ManifestSong{{DownloadTask: a, FilePath: "Trip/a.flac"}}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed = %+v, want %+v", removed, want)
+	}
+
+	folder := t.TempDir()
+	if err := m.Save(folder); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadManifest(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read.Songs, m.Songs) || read.Task() != m.Task() || !read.SyncedAt.Equal(m.SyncedAt) {
+		t.Errorf("ReadManifest = %+v, want %+v", read, m)
+	}
+}
@@ -0,0 +1,184 @@
+package leumusic
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// DefaultMatchThreshold is the similarity above which two titles are
+// considered the same song.
+const DefaultMatchThreshold = 0.85
+
+// Matcher decides whether two songs are the same after folding case and
+// diacritics and dropping decorations such as "(Official Video)", "feat."
+// credits and remaster suffixes.
+type Matcher struct {
+	// Threshold is the minimum Similarity score, from 0 to 1.
+	// Zero means DefaultMatchThreshold.
+	Threshold float64
+}
+
+func (m Matcher) threshold() float64 {
+	if m.Threshold <= 0 || m.Threshold > 1 {
+		return DefaultMatchThreshold
+	}
+	return m.Threshold
+}
+
+// Same reports whether a and b name the same song, and their score.
+func (m Matcher) Same(a, b DownloadTask) (float64, bool) {
+	artist := Similarity(a.Artist, b.Artist)
+	song := Similarity(a.Song, b.Song)
+	score := min(artist, song)
+	return score, score >= m.threshold()
+}
+
+// MatchFile reports whether a downloaded file name (with or without the
+// artist in front) is the given song, and its score.
+func (m Matcher) MatchFile(artist, song, filename string) (float64, bool) {
+	title := NormalizeTitle(strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename)))
+	score := titleScore(NormalizeTitle(artist), NormalizeTitle(song), title)
+	return score, score >= m.threshold()
+}
+
+// titleScore compares a normalized title that may or may not start with
+// the artist against a normalized artist and song.
+func titleScore(artist, song, title string) float64 {
+	score := similarity(song, title)
+	if artist != "" {
+		score = max(score, similarity(artist+" "+song, title))
+		if rest, ok := strings.CutPrefix(title, artist+" "); ok {
+			score = max(score, similarity(song, rest))
+		}
+	}
+	return score
+}
+
+var (
+	noiseGroup = regexp.MustCompile(`[(\[{][^)\]}]*\b(official|video|videoclip|audio|lyrics?|letra|visuali[sz]er|hd|hq|4k|remaster(ed)?|remasterizad[oa]|feat|ft|featuring|explicit|clip)\b[^)\]}]*[)\]}]`)
+	featCredit = regexp.MustCompile(`\s(feat|ft|featuring)\b\.?.*$`)
+	remaster   = regexp.MustCompile(`\s-\s[^-]*\bremaster(ed)?\b[^-]*$`)
+	apostrophe = strings.NewReplacer("'", "", "’", "", "`", "")
+)
+
+// NormalizeTitle folds case and diacritics, removes common title
+// decorations and punctuation, and collapses whitespace.
+func NormalizeTitle(s string) string {
+	s = foldDiacritics(strings.ToLower(s))
+	s = noiseGroup.ReplaceAllString(s, " ")
+	s = remaster.ReplaceAllString(s, "")
+	s = featCredit.ReplaceAllString(s, "")
+	s = apostrophe.Replace(s)
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return !isWordRune(r)
+	}), " ")
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Similarity scores two titles from 0 (unrelated) to 1 (same after
+// NormalizeTitle). It is the better of a word-overlap score and an edit
+// distance ratio, so both reordered words and small typos score high while
+// a short title contained in a longer one does not. Numbers are never
+// typos: titles whose numbers differ, such as "Symphony No. 5" and
+// "Symphony No. 9", score 0.
+func Similarity(a, b string) float64 {
+	return similarity(NormalizeTitle(a), NormalizeTitle(b))
+}
+
+func similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	if a == "" || b == "" || !slices.Equal(numbers(a), numbers(b)) {
+		return 0
+	}
+	return max(wordOverlap(a, b), editRatio(a, b))
+}
+
+var digits = regexp.MustCompile(`\d+`)
+
+// numbers returns the runs of digits in s, in order and without leading
+// zeros, so "Track 02" and "Track 2" still match.
+func numbers(s string) []string {
+	runs := digits.FindAllString(s, -1)
+	for i, run := range runs {
+		if trimmed := strings.TrimLeft(run, "0"); trimmed != "" {
+			runs[i] = trimmed
+		} else {
+			runs[i] = "0"
+		}
+	}
+	return runs
+}
+
+func wordOverlap(a, b string) float64 {
+	wordsA := strings.Fields(a)
+	wordsB := strings.Fields(b)
+	counts := map[string]int{}
+	for _, w := range wordsA {
+		counts[w]++
+	}
+	shared := 0
+	for _, w := range wordsB {
+		if counts[w] > 0 {
+			counts[w]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(wordsA)+len(wordsB))
+}
+
+func editRatio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(max(len(ra), len(rb)))
+}
+
+var diacritics = map[rune]string{}
+
+func init() {
+	for base, variants := range map[string]string{
+		"a": "àáâãäåāăą", "c": "çćĉċč", "d": "ďđ", "e": "èéêëēĕėęě",
+		"g": "ĝğġģ", "h": "ĥħ", "i": "ìíîïĩīĭįı", "j": "ĵ", "k": "ķ",
+		"l": "ĺļľŀł", "n": "ñńņňŉ", "o": "òóôõöøōŏő", "r": "ŕŗř",
+		"s": "śŝşš", "t": "ţťŧ", "u": "ùúûüũūŭůűų", "w": "ŵ", "y": "ýÿŷ",
+		"z": "źżž", "ae": "æ", "oe": "œ", "ss": "ß", "th": "þ",
+	} {
+		for _, r := range variants {
+			diacritics[r] = base
+		}
+	}
+}
+
+func foldDiacritics(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if base, ok := diacritics[r]; ok {
+			b.WriteString(base)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
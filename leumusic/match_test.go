@@ -0,0 +1,85 @@
+package leumusic
+
+import "testing"
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Queen - Bohemian Rhapsody", "queen bohemian rhapsody"},
+		{"Bohemian Rhapsody (Official Video)", "bohemian rhapsody"},
+		{"Despacito ft. Daddy Yankee", "despacito"},
+		{"Canción Número Uno", "cancion numero uno"},
+		{"Don't Stop Me Now - Remastered 2011", "dont stop me now"},
+		{"  Song   [HD]  ", "song"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeTitle(tt.in); got != tt.want {
+			t.Errorf("NormalizeTitle(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSame(t *testing.T) {
+	tests := []struct {
+		a, b DownloadTask
+		want bool
+	}{
+		{DownloadTask{Artist: "Queen", Song: "Bohemian Rhapsody"}, DownloadTask{Artist: "queen", Song: "bohemian rhapsody"}, true},
+		{DownloadTask{Artist: "Beyoncé", Song: "Halo"}, DownloadTask{Artist: "Beyonce", Song: "Halo (Official Video)"}, true},
+		{DownloadTask{Artist: "Queen", Song: "Bohemian Rhapsody"}, DownloadTask{Artist: "Queen", Song: "Bohemian Rapsody"}, true},
+		{DownloadTask{Artist: "Metallica", Song: "One"}, DownloadTask{Artist: "Metallica", Song: "One More Time"}, false},
+		{DownloadTask{Artist: "U2", Song: "One"}, DownloadTask{Artist: "U2", Song: "One Tree Hill"}, false},
+		{DownloadTask{Artist: "Beethoven", Song: "Symphony No. 5"}, DownloadTask{Artist: "Beethoven", Song: "Symphony No. 9"}, false},
+		{DownloadTask{Artist: "Blur", Song: "Song 2"}, DownloadTask{Artist: "Blur", Song: "Song 3"}, false},
+		{DownloadTask{Artist: "Blur", Song: "Song 2"}, DownloadTask{Artist: "Blur", Song: "Song 02"}, true},
+		{DownloadTask{Artist: "Queen", Song: "Bohemian Rhapsody"}, DownloadTask{Artist: "ABBA", Song: "Bohemian Rhapsody"}, false},
+	}
+	var m Matcher
+	for _, tt := range tests {
+		if score, got := m.Same(tt.a, tt.b); got != tt.want {
+			t.Errorf("Same(%q, %q) = %v (%.3f), want %v", tt.a.Key(), tt.b.Key(), got, score, tt.want)
+		}
+	}
+}
+
+func TestMatchFile(t *testing.T) {
+	tests := []struct {
+		artist, song, file string
+		want               bool
+	}{
+		{"Queen", "Bohemian Rhapsody", "queen - bohemian rhapsody.mp3", true},
+		{"Queen", "Bohemian Rhapsody", "Bohemian Rhapsody.mp3", true},
+		{"Queen", "Bohemian Rhapsody", "Queen - Bohemian Rhapsody (Official Video).flac", true},
+		{"Metallica", "One", "Metallica - One More Time.mp3", false},
+		{"Metallica", "One", "Someone Like You.mp3", false},
+		{"Beethoven", "Symphony No. 5", "Beethoven - Symphony No. 9.mp3", false},
+		{"Beethoven", "Symphony No. 5", "Symphony No. 5.mp3", true},
+		{"Blur", "Song 2", "Blur - Song 3.mp3", false},
+		{"Blink-182", "All the Small Things", "Blink-182 - All the Small Things.mp3", true},
+	}
+	var m Matcher
+	for _, tt := range tests {
+		if score, got := m.MatchFile(tt.artist, tt.song, tt.file); got != tt.want {
+			t.Errorf("MatchFile(%q, %q, %q) = %v (%.3f), want %v", tt.artist, tt.song, tt.file, got, score, tt.want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		min, max float64
+	}{
+		{"Bohemian Rhapsody", "bohemian rhapsody", 1, 1},
+		{"Rhapsody Bohemian", "Bohemian Rhapsody", 1, 1},
+		{"Symphony No. 5", "Symphony No. 9", 0, 0},
+		{"", "Song", 0, 0},
+		{"One", "One More Time", 0, 0.6},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); got < tt.min || got > tt.max {
+			t.Errorf("Similarity(%q, %q) = %.3f, want between %.2f and %.2f", tt.a, tt.b, got, tt.min, tt.max)
+		}
+	}
+}
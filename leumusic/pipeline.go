@@ -0,0 +1,539 @@
+package leumusic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Pipeline defaults for unset Options.
+const (
+	DefaultQueueSize      = 8
+	DefaultResolveWorkers = 4
+)
+
+// sourceSuffix marks fetched audio that still has to be transcoded.
+const sourceSuffix = ".source"
+
+// Stage is one step of the download pipeline. Every task is resolved to a
+// search result, its audio is fetched, then ffmpeg turns it into a tagged
+// MP3. Each stage has its own workers and a bounded queue in front of it.
+type Stage int
+
+const (
+	StageResolve Stage = iota
+	StageFetch
+	StageTranscode
+	stageCount
+)
+
+// StageCount is how many tasks wait for a stage and how many it is
+// working on.
+type StageCount struct {
+	Queued int
+	Active int
+}
+
+// stageCounter tracks a StageCount while Download runs.
+type stageCounter struct {
+	queued atomic.Int32
+	active atomic.Int32
+}
+
+func (c *stageCounter) start() {
+	c.queued.Add(-1)
+	c.active.Add(1)
+}
+
+// Stages returns what each stage is doing, indexed by Stage.
+func (d *Downloader) Stages() []StageCount {
+	counts := make([]StageCount, stageCount)
+	for i := range counts {
+		counts[i] = StageCount{
+			Queued: int(d.stages[i].queued.Load()),
+			Active: int(d.stages[i].active.Load()),
+		}
+	}
+	return counts
+}
+
+// job is a task on its way through the pipeline.
+type job struct {
+	*taskRun
+	// stop ends the task's timeout.
+	stop    context.CancelFunc
+	start   time.Time
+	folder  string
+	filter  Filter
+	skipped bool
+
+	// providers are the ones not searched yet. candidates are the results
+	// of the last search that passed the filter, next the first one not
+	// tried yet, and all every result of that search.
+	providers   []Provider
+	candidates  []Candidate
+	next        int
+	all         []Candidate
+	matchFilter string
+	attempts    int
+	lastErr     error
+
+	info downloadInfo
+	// raw and thumbnail are the files fetched for the transcode stage.
+	raw       string
+	thumbnail string
+}
+
+// Download runs every task and returns one Result per task, in the same
+// order. Once ctx is done no new tasks are started, and the yt-dlp and
+// ffmpeg processes still running are killed; their tasks fail with the
+// cause of ctx. Cancel stops a single task the same way.
+func (d *Downloader) Download(ctx context.Context, tasks []DownloadTask) []Result {
+	results := make([]Result, len(tasks))
+	fetchQueue := make(chan *job, d.opts.QueueSize)
+	transcodeQueue := make(chan *job, d.opts.QueueSize)
+
+	resolvers := newPool(d.opts.ResolveWorkers)
+	fetchers := newPool(d.Workers())
+	transcoders := newPool(d.opts.TranscodeWorkers)
+
+	if d.adaptive {
+		stop := make(chan struct{})
+		defer close(stop)
+		go d.adapt(fetchers, stop)
+	}
+
+	contexts := d.taskContexts(ctx, len(tasks))
+	finish := func(j *job, err error) {
+		if err != nil {
+			j.discardFetched()
+		}
+		results[j.index] = d.finish(j, err)
+		j.stop()
+		d.release(j.index)
+		d.finished.Add(1)
+	}
+
+	var resolving sync.WaitGroup
+	d.stages[StageResolve].queued.Store(int32(len(tasks)))
+	go func() {
+		for i, task := range tasks {
+			if !resolvers.acquire(contexts[i]) {
+				err := context.Cause(contexts[i])
+				d.release(i)
+				d.stages[StageResolve].queued.Add(-1)
+				results[i] = Result{Task: task, Status: StatusFailed, Err: err}
+				d.emit(Event{Kind: EventFailed, Index: i, Task: task, Err: err})
+				continue
+			}
+			resolving.Add(1)
+			go func(j *job) {
+				defer resolving.Done()
+				defer resolvers.release()
+				d.runStage(StageResolve, j, fetchQueue, d.resolve, finish)
+			}(d.newJob(contexts[i], task, i))
+		}
+		resolving.Wait()
+		close(fetchQueue)
+	}()
+
+	go func() {
+		if d.opts.BatchSize > 1 {
+			d.drainBatches(ctx, fetchers, fetchQueue, transcodeQueue, finish)
+		} else {
+			d.drain(ctx, StageFetch, fetchers, fetchQueue, transcodeQueue, d.fetch, finish)
+		}
+		close(transcodeQueue)
+	}()
+	d.drain(ctx, StageTranscode, transcoders, transcodeQueue, nil, d.transcode, finish)
+	// Each fetch's folder is removed after transcoding, so this only goes
+	// if none were left behind, unless the run was cancelled.
+	if ctx.Err() != nil {
+		os.RemoveAll(filepath.Join(d.opts.OutputDir, workFolder))
+	} else {
+		os.Remove(filepath.Join(d.opts.OutputDir, workFolder))
+	}
+
+	return results
+}
+
+// drain runs a stage on every job from in, with at most p's limit of them
+// at once, until in is closed and every job has left the stage.
+func (d *Downloader) drain(ctx context.Context, stage Stage, p *pool, in <-chan *job, out chan<- *job, fn func(*job) (bool, error), finish func(*job, error)) {
+	var wg sync.WaitGroup
+	for j := range in {
+		// Jobs already in the pipeline still get to finish, with ctx's
+		// error, once ctx is done.
+		p.acquire(context.WithoutCancel(ctx))
+		wg.Add(1)
+		go func(j *job) {
+			defer wg.Done()
+			defer p.release()
+			d.runStage(stage, j, out, fn, finish)
+		}(j)
+	}
+	wg.Wait()
+}
+
+// runStage runs fn on j, then hands j to out, or finishes it if fn says it
+// is done or there is no next stage.
+func (d *Downloader) runStage(stage Stage, j *job, out chan<- *job, fn func(*job) (bool, error), finish func(*job, error)) {
+	d.stages[stage].start()
+	if j.ctx.Err() != nil {
+		d.leaveStage(stage, j, out, false, context.Cause(j.ctx), finish)
+		return
+	}
+	done, err := fn(j)
+	d.leaveStage(stage, j, out, done, err, finish)
+}
+
+// leaveStage hands j to out, or finishes it if it is done, failed or there
+// is no next stage.
+func (d *Downloader) leaveStage(stage Stage, j *job, out chan<- *job, done bool, err error, finish func(*job, error)) {
+	d.stages[stage].active.Add(-1)
+	if done || err != nil || out == nil {
+		finish(j, err)
+		return
+	}
+	d.stages[stage+1].queued.Add(1)
+	out <- j
+}
+
+func (d *Downloader) newJob(ctx context.Context, t DownloadTask, index int) *job {
+	stop := context.CancelFunc(func() {})
+	if d.opts.TaskTimeout > 0 {
+		timeout := &DownloadError{Kind: ErrorTimeout, Message: "took longer than " + d.opts.TaskTimeout.String(), Attempts: 1}
+		ctx, stop = context.WithTimeoutCause(ctx, d.opts.TaskTimeout, timeout)
+	}
+	return &job{
+		stop: stop,
+		taskRun: &taskRun{
+			ctx:     ctx,
+			task:    t,
+			index:   index,
+			cookies: !t.NoCookies && (d.opts.UseCookies || d.stickyCookies.Load()),
+		},
+		start:     time.Now(),
+		filter:    d.opts.Filter.Merge(t.Filter),
+		providers: d.opts.Providers,
+	}
+}
+
+// resolve finds what to download for a job. It reports done for songs that
+// are already downloaded.
+func (d *Downloader) resolve(j *job) (bool, error) {
+	t := j.task
+	d.emit(Event{Kind: EventStarted, Index: j.index, Task: t})
+	if t.Playlist != "" {
+		j.folder = d.artistFolder(t.Playlist)
+	} else {
+		j.folder = d.artistFolder(t.Artist)
+	}
+
+	if t.Song != "" {
+		if path, ok := d.isAlreadyDownloaded(t.Artist, t.Song, j.folder); ok {
+			j.info.Filepath = path
+			j.skipped = true
+			return true, nil
+		}
+	}
+	if t.URL != "" {
+		j.candidates, j.next = []Candidate{{URL: t.URL}}, 0
+		return false, nil
+	}
+	return false, d.nextProvider(j)
+}
+
+// nextProvider searches the providers left until one has candidates.
+func (d *Downloader) nextProvider(j *job) error {
+	t := j.task
+	for len(j.providers) > 0 {
+		provider := j.providers[0]
+		j.providers = j.providers[1:]
+
+		if d.opts.SearchResults == 1 {
+			// yt-dlp takes the first hit itself, with the filter as a
+			// --match-filter.
+			query := fmt.Sprintf("%s1:%s %s", provider, t.Artist, t.Song)
+			j.candidates, j.next = []Candidate{{Provider: provider, Rank: 1, URL: query}}, 0
+			j.matchFilter = j.filter.matchFilter(t.Song)
+			return nil
+		}
+
+		var candidates []Candidate
+		err := d.retry(j.taskRun, "yt-dlp", func() (err error) {
+			candidates, err = d.searchCandidates(j.ctx, provider, t, d.opts.SearchResults, j.filter, j.cookies)
+			return err
+		})
+		if len(candidates) > 0 {
+			d.emit(Event{Kind: EventResolved, Index: j.index, Task: t, Candidates: candidates, Err: err})
+		}
+		if err != nil {
+			if d.fatal(err) {
+				return err
+			}
+			j.lastErr = err
+			continue
+		}
+
+		j.all, j.candidates, j.next = candidates, candidates, 0
+		for i, c := range candidates {
+			if c.Rejected != "" {
+				j.candidates = candidates[:i]
+				break
+			}
+		}
+		return nil
+	}
+	return j.failure()
+}
+
+// failure is the error of a job that ran out of candidates.
+func (j *job) failure() error {
+	if j.attempts > 1 {
+		return fmt.Errorf("%d candidates failed, last: %w", j.attempts, j.lastErr)
+	}
+	return j.lastErr
+}
+
+// fetch downloads the audio of the best candidate left, falling back to
+// the next ones and then to the next providers.
+func (d *Downloader) fetch(j *job) (done bool, err error) {
+	work, err := d.workFolder()
+	if err != nil {
+		return false, &DownloadError{Kind: ErrorDisk, Message: err.Error(), Attempts: 1}
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(work)
+		}
+	}()
+
+	template := filepath.Join(work, "%(title)s"+sourceSuffix+".%(ext)s")
+	for {
+		for j.next < len(j.candidates) {
+			c := j.candidates[j.next]
+			j.next++
+
+			var info downloadInfo
+			err := d.retry(j.taskRun, "yt-dlp", func() (err error) {
+				info, err = d.fetchAudio(j.ctx, c.URL, template, j.matchFilter, j.cookies)
+				return err
+			})
+			j.attempts++
+			if err == nil {
+				info.Provider, info.Rank = c.Provider, c.Rank
+				j.info = info
+				j.raw = info.Filepath
+				j.thumbnail = findThumbnail(info.Filepath)
+				return false, nil
+			}
+			j.lastErr = err
+			if d.fatal(err) || j.task.URL != "" {
+				return false, err
+			}
+			if j.matchFilter == "" {
+				d.emit(Event{Kind: EventFallback, Index: j.index, Task: j.task, Candidates: j.all[j.next-1:], Err: err})
+			}
+		}
+		if len(j.providers) == 0 {
+			return false, j.failure()
+		}
+		if err := d.nextProvider(j); err != nil {
+			return false, err
+		}
+	}
+}
+
+// fetchAudio downloads the best audio stream of target as is, with its
+// thumbnail, leaving the conversion to the transcode stage.
+func (d *Downloader) fetchAudio(ctx context.Context, target, outputTemplate, matchFilter string, cookies bool) (downloadInfo, error) {
+	args := fetchArgs(outputTemplate)
+
+	if cookies {
+		args = append(args, d.cookieSource(target).args()...)
+	}
+
+	if matchFilter != "" {
+		args = append(args, "--match-filter", matchFilter)
+	}
+
+	args = append(args, target)
+
+	output, err := d.runYtDlp(ctx, args...)
+	if errors.Is(err, ErrToolMissing) {
+		return downloadInfo{}, err
+	}
+	outputStr := string(output)
+	info := parseDownloadInfo(outputStr)
+	if err != nil {
+		return info, classifyOutput(outputStr, err)
+	}
+	if info.Filepath == "" {
+		if matchFilter != "" {
+			return info, fmt.Errorf("%w: nothing matched the filter", ErrNoCandidates)
+		}
+		return info, classifyOutput(outputStr, errors.New("yt-dlp did not report a file"))
+	}
+
+	return info, nil
+}
+
+func fetchArgs(outputTemplate string) []string {
+	return []string{
+		"--format", "bestaudio/best",
+		"--write-thumbnail",
+		"--no-playlist",
+		"--socket-timeout", "30",
+		"--retries", "3",
+		"--fragment-retries", "3",
+		"--ignore-errors",
+		"--no-warnings",
+		"--no-simulate",
+		"--print", infoTemplate,
+		"--output", outputTemplate,
+	}
+}
+
+// workFolder is where fetches go, each in a folder of its own, until the
+// transcode stage writes the MP3 into the artist folder. Whatever a killed
+// yt-dlp leaves there can be removed without touching other songs.
+const workFolder = ".leumusic-work"
+
+func (d *Downloader) workFolder() (string, error) {
+	parent := filepath.Join(d.opts.OutputDir, workFolder)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return "", err
+	}
+	return os.MkdirTemp(parent, "fetch-")
+}
+
+// discardFetched removes the folder of the files fetched for j.
+func (j *job) discardFetched() {
+	if j.raw != "" {
+		os.RemoveAll(filepath.Dir(j.raw))
+	}
+}
+
+// thumbnailExts are the image formats yt-dlp writes thumbnails in.
+var thumbnailExts = []string{".jpg", ".webp", ".png", ".jpeg"}
+
+func findThumbnail(media string) string {
+	base := strings.TrimSuffix(media, filepath.Ext(media))
+	for _, ext := range thumbnailExts {
+		if _, err := os.Stat(base + ext); err == nil {
+			return base + ext
+		}
+	}
+	return ""
+}
+
+// transcode converts the fetched audio to a tagged MP3, or the task's
+// format, with its cover.
+func (d *Downloader) transcode(j *job) (bool, error) {
+	name := strings.TrimSuffix(filepath.Base(j.raw), filepath.Ext(j.raw))
+	ext := "." + string(j.task.format())
+	out := filepath.Join(j.folder, strings.TrimSuffix(name, sourceSuffix)+ext)
+	defer j.discardFetched()
+
+	// Like yt-dlp's --no-overwrites, keep a file that is already there.
+	if _, err := os.Stat(out); err == nil {
+		j.info.Filepath = out
+		return false, nil
+	}
+
+	// ffmpeg writes into the work folder, so a crash never leaves a half
+	// written song where it would be taken for a downloaded one.
+	tmp := filepath.Join(filepath.Dir(j.raw), filepath.Base(out))
+	err := d.retry(j.taskRun, "ffmpeg", func() error {
+		output, err := d.opts.Tools.RunContext(j.ctx, "ffmpeg", d.ffmpegArgs(j, tmp)...)
+		if errors.Is(err, ErrToolMissing) {
+			return err
+		}
+		if err != nil {
+			os.Remove(tmp)
+			return &DownloadError{Kind: ErrorPostprocess, Message: "ffmpeg: " + lastLine(string(output)), Attempts: 1}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp, out); err != nil {
+		return false, &DownloadError{Kind: ErrorDisk, Message: err.Error(), Attempts: 1}
+	}
+	j.info.Filepath = out
+	return false, nil
+}
+
+func (d *Downloader) ffmpegArgs(j *job, output string) []string {
+	args := []string{"-hide_banner", "-loglevel", "error", "-y", "-i", j.raw}
+	if j.thumbnail != "" {
+		args = append(args, "-i", j.thumbnail, "-map", "0:a", "-map", "1:v",
+			"-c:v", "mjpeg", "-disposition:v", "attached_pic",
+			"-metadata:s:v", "title=Album cover", "-metadata:s:v", "comment=Cover (front)")
+	} else {
+		args = append(args, "-map", "0:a")
+	}
+	format := j.task.format()
+	if format == FormatFLAC {
+		args = append(args, "-codec:a", "flac")
+	} else {
+		args = append(args, "-codec:a", "libmp3lame", "-q:a", d.opts.Quality.audioQuality(), "-id3v2_version", "3")
+	}
+
+	info := j.info
+	artist := info.Artist
+	if artist == "" {
+		artist = info.Uploader
+	}
+	album := info.Album
+	if j.task.Album != "" {
+		album = j.task.Album
+	}
+	tags := [][2]string{
+		{"title", info.Title},
+		{"artist", artist},
+		{"album", album},
+		{"date", strconv.Itoa(info.ReleaseYear)},
+		{"comment", info.WebpageURL},
+	}
+	for _, tag := range tags {
+		if tag[1] != "" && tag[1] != "0" {
+			args = append(args, "-metadata", tag[0]+"="+tag[1])
+		}
+	}
+	return append(args, "-f", string(format), output)
+}
+
+// finish reports the outcome of a job and records it in the ledger.
+func (d *Downloader) finish(j *job, err error) Result {
+	t, index := j.task, j.index
+	result := Result{Task: t, Status: StatusSucceeded, Err: err, Duration: time.Since(j.start)}
+	switch {
+	case err != nil:
+		result.Status = StatusFailed
+		d.emit(Event{Kind: EventFailed, Index: index, Task: t, Err: err})
+		return result
+	case j.skipped:
+		result.Status = StatusSkipped
+		d.emit(Event{Kind: EventSkipped, Index: index, Task: t})
+	default:
+		d.emit(Event{Kind: EventSucceeded, Index: index, Task: t})
+	}
+
+	// A song skipped because the ledger has it is recorded already.
+	if t.Song != "" && d.opts.Ledger != nil && (!j.skipped || j.info.Filepath != "") {
+		if err := d.opts.Ledger.Add(d.record(t, j.info)); err != nil {
+			d.emit(Event{Kind: EventLedgerError, Index: index, Task: t, Err: err})
+		}
+	}
+	return result
+}
@@ -0,0 +1,222 @@
+package leumusic
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IsPlaylistURL reports whether target is a YouTube or YouTube Music
+// playlist or channel, or a SoundCloud set, rather than a single video. A
+// video URL that also names a playlist ("watch?v=...&list=...") is a
+// video.
+func IsPlaylistURL(target string) bool {
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	switch host {
+	case "youtube.com", "m.youtube.com", "music.youtube.com":
+		if u.Path == "/playlist" {
+			return u.Query().Get("list") != ""
+		}
+		for _, prefix := range []string{"/@", "/channel/", "/c/", "/user/", "/browse/"} {
+			if strings.HasPrefix(u.Path, prefix) {
+				return true
+			}
+		}
+	case "soundcloud.com":
+		return strings.Contains(u.Path, "/sets/")
+	}
+	return false
+}
+
+// playlistTarget points a bare YouTube channel URL at its videos; without
+// a tab, yt-dlp lists the channel's tabs instead.
+func playlistTarget(target string) string {
+	u, err := url.Parse(target)
+	if err != nil || !strings.Contains(u.Host, "youtube.com") || u.Host == "music.youtube.com" {
+		return target
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	channel := strings.HasPrefix(parts[0], "@") && len(parts) == 1 ||
+		(parts[0] == "channel" || parts[0] == "c" || parts[0] == "user") && len(parts) == 2
+	if !channel {
+		return target
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/videos"
+	return u.String()
+}
+
+const playlistEntryTemplate = "%(.{id,title,url,webpage_url,channel,uploader,artist,track,duration,playlist_title,playlist_id})j"
+
+// playlistEntry is what yt-dlp lists for each video of a playlist.
+type playlistEntry struct {
+	ID            string  `json:"id"`
+	Title         string  `json:"title"`
+	URL           string  `json:"url"`
+	WebpageURL    string  `json:"webpage_url"`
+	Channel       string  `json:"channel"`
+	Uploader      string  `json:"uploader"`
+	Artist        string  `json:"artist"`
+	Track         string  `json:"track"`
+	Duration      float64 `json:"duration"`
+	PlaylistTitle string  `json:"playlist_title"`
+	PlaylistID    string  `json:"playlist_id"`
+}
+
+// ExpandPlaylist lists the videos of the playlist or channel at
+// playlist.URL, in order, as tasks pinned to each video and saved in the
+// playlist's folder. They keep the other options of playlist, and its
+// Playlist name if set. The artist and song come from the video's music
+// metadata when it has some, then from an "artist - song" title, then from
+// the channel and title.
+func (d *Downloader) ExpandPlaylist(ctx context.Context, playlist DownloadTask) (PlaylistInfo, []DownloadTask, error) {
+	target := playlist.URL
+	info := PlaylistInfo{URL: target}
+	args := []string{
+		"--flat-playlist",
+		"--skip-download",
+		"--no-warnings",
+		"--ignore-errors",
+		"--socket-timeout", "30",
+		"--print", playlistEntryTemplate,
+	}
+	run := &taskRun{ctx: ctx, task: playlist, index: -1, cookies: !playlist.NoCookies && (d.opts.UseCookies || d.stickyCookies.Load())}
+
+	var entries []playlistEntry
+	err := d.retry(run, "yt-dlp", func() error {
+		runArgs := args
+		if run.cookies {
+			runArgs = append(runArgs, d.cookieSource(target).args()...)
+		}
+		output, err := d.runYtDlp(ctx, append(runArgs, playlistTarget(target))...)
+		if errors.Is(err, ErrToolMissing) {
+			return err
+		}
+		entries = parsePlaylistEntries(string(output))
+		if len(entries) == 0 {
+			if err == nil {
+				err = errors.New("yt-dlp listed no videos")
+			}
+			return classifyOutput(string(output), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return info, nil, err
+	}
+
+	info.Name = playlist.Playlist
+	if info.Name == "" {
+		info.Name = entries[0].PlaylistTitle
+	}
+	if info.Name == "" {
+		info.Name = entries[0].PlaylistID
+	}
+	tasks := make([]DownloadTask, 0, len(entries))
+	for _, e := range entries {
+		t := e.task(info.Name)
+		t.Filter, t.Format, t.Album, t.NoCookies = playlist.Filter, playlist.Format, playlist.Album, playlist.NoCookies
+		tasks = append(tasks, t)
+	}
+	return info, tasks, nil
+}
+
+func parsePlaylistEntries(output string) []playlistEntry {
+	var entries []playlistEntry
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var e playlistEntry
+		if json.Unmarshal([]byte(line), &e) != nil || (e.ID == "" && e.URL == "") {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// task turns the entry into a task for the playlist named playlist.
+func (e playlistEntry) task(playlist string) DownloadTask {
+	t := DownloadTask{Artist: e.Artist, Song: e.Track, URL: e.WebpageURL, Playlist: playlist}
+	if t.Artist == "" || t.Song == "" {
+		artist, song, ok := strings.Cut(e.Title, " - ")
+		if artist, song = strings.TrimSpace(artist), strings.TrimSpace(song); !ok || artist == "" || song == "" {
+			artist, song = channelArtist(e.Channel, e.Uploader), e.Title
+		}
+		t.Artist, t.Song = artist, song
+	}
+	if t.URL == "" {
+		t.URL = e.URL
+	}
+	if !strings.Contains(t.URL, "://") && e.ID != "" {
+		t.URL = "https://www.youtube.com/watch?v=" + e.ID
+	}
+	return t
+}
+
+// channelArtist is the artist behind a channel name such as "Queen - Topic"
+// or "QueenVEVO".
+func channelArtist(channel, uploader string) string {
+	if channel == "" {
+		channel = uploader
+	}
+	channel = strings.TrimSuffix(channel, " - Topic")
+	channel = strings.TrimSuffix(channel, "VEVO")
+	return strings.TrimSpace(channel)
+}
+
+// PlaylistEntry is one song of a playlist file.
+type PlaylistEntry struct {
+	// Path is the audio file, and Title what players show for it.
+	Path     string
+	Title    string
+	Duration float64
+}
+
+// WriteM3U8 writes entries in order as an extended M3U playlist at path,
+// with file paths relative to the playlist so the folder can be moved.
+func WriteM3U8(path string, entries []PlaylistEntry) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	fmt.Fprintln(w, "#EXTM3U")
+	for _, e := range entries {
+		rel, err := filepath.Rel(dir, e.Path)
+		if err != nil {
+			rel = e.Path
+		}
+		duration := -1
+		if e.Duration > 0 {
+			duration = int(math.Round(e.Duration))
+		}
+		fmt.Fprintf(w, "#EXTINF:%d,%s\n%s\n", duration, e.Title, filepath.ToSlash(rel))
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,78 @@
+package leumusic
+
+import "testing"
+
+func TestPlaylistURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		playlist bool
+		target   string
+	}{
+		{"https://www.youtube.com/watch?v=abc", false, ""},
+		{"https://www.youtube.com/watch?v=abc&list=PL1", false, ""},
+		{"https://youtu.be/abc?list=PL1", false, ""},
+		{"https://www.youtube.com/playlist?list=PL1", true, "https://www.youtube.com/playlist?list=PL1"},
+		{"https://www.youtube.com/playlist", false, ""},
+		{"https://m.youtube.com/playlist?list=PL1", true, "https://m.youtube.com/playlist?list=PL1"},
+		{"https://music.youtube.com/playlist?list=OLAK5uy_x", true, "https://music.youtube.com/playlist?list=OLAK5uy_x"},
+		{"https://music.youtube.com/watch?v=abc&list=RDAMVMabc", false, ""},
+		{"https://music.youtube.com/browse/MPREb_x", true, "https://music.youtube.com/browse/MPREb_x"},
+		{"https://www.youtube.com/@queenofficial", true, "https://www.youtube.com/@queenofficial/videos"},
+		{"https://www.youtube.com/@queenofficial/", true, "https://www.youtube.com/@queenofficial/videos"},
+		{"https://www.youtube.com/@queenofficial/shorts", true, "https://www.youtube.com/@queenofficial/shorts"},
+		{"https://www.youtube.com/channel/UCiMhD4jzUqG-IgPzUmmytRQ", true, "https://www.youtube.com/channel/UCiMhD4jzUqG-IgPzUmmytRQ/videos"},
+		{"https://www.youtube.com/c/Queen", true, "https://www.youtube.com/c/Queen/videos"},
+		{"https://www.youtube.com/user/QueenVEVO", true, "https://www.youtube.com/user/QueenVEVO/videos"},
+		{"https://soundcloud.com/queen-69312/sets/greatest-hits", true, "https://soundcloud.com/queen-69312/sets/greatest-hits"},
+		{"https://soundcloud.com/queen-69312/bohemian-rhapsody", false, ""},
+		{"https://vimeo.com/channels/staffpicks", false, ""},
+	}
+	for _, tt := range tests {
+		if got := IsPlaylistURL(tt.url); got != tt.playlist {
+			t.Errorf("IsPlaylistURL(%q) = %v, want %v", tt.url, got, tt.playlist)
+		}
+		if !tt.playlist {
+			continue
+		}
+		if got := playlistTarget(tt.url); got != tt.target {
+			t.Errorf("playlistTarget(%q) = %q, want %q", tt.url, got, tt.target)
+		}
+	}
+}
+
+func TestPlaylistEntryTask(t *testing.T) {
+	tests := []struct {
+		entry playlistEntry
+		want  DownloadTask
+	}{
+		{
+			playlistEntry{ID: "a", Title: "Dancing Queen", Artist: "ABBA", Track: "Dancing Queen", Channel: "ABBAVEVO", WebpageURL: "https://www.youtube.com/watch?v=a"},
+			DownloadTask{Artist: "ABBA", Song: "Dancing Queen", URL: "https://www.youtube.com/watch?v=a", Playlist: "Trip"},
+		},
+		{
+			playlistEntry{ID: "b", Title: "Queen - Bohemian Rhapsody (Official Video)", Channel: "Queen Official", URL: "https://www.youtube.com/watch?v=b"},
+			DownloadTask{Artist: "Queen", Song: "Bohemian Rhapsody (Official Video)", URL: "https://www.youtube.com/watch?v=b", Playlist: "Trip"},
+		},
+		{
+			playlistEntry{ID: "c", Title: "Wonderwall", Channel: "Oasis - Topic"},
+			DownloadTask{Artist: "Oasis", Song: "Wonderwall", URL: "https://www.youtube.com/watch?v=c", Playlist: "Trip"},
+		},
+		{
+			playlistEntry{ID: "d", Title: "Halo", Uploader: "BeyonceVEVO", URL: "d"},
+			DownloadTask{Artist: "Beyonce", Song: "Halo", URL: "https://www.youtube.com/watch?v=d", Playlist: "Trip"},
+		},
+		{
+			playlistEntry{ID: "e", Title: " - Intro", Channel: "Someone"},
+			DownloadTask{Artist: "Someone", Song: " - Intro", URL: "https://www.youtube.com/watch?v=e", Playlist: "Trip"},
+		},
+		{
+			playlistEntry{Title: "Innuendo", Uploader: "queen-69312", URL: "https://soundcloud.com/queen-69312/innuendo"},
+			DownloadTask{Artist: "queen-69312", Song: "Innuendo", URL: "https://soundcloud.com/queen-69312/innuendo", Playlist: "Trip"},
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.entry.task("Trip"); got != tt.want {
+			t.Errorf("task(%+v) = %+v, want %+v", tt.entry, got, tt.want)
+		}
+	}
+}
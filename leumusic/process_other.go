@@ -0,0 +1,8 @@
+//go:build !unix
+
+package leumusic
+
+import "os/exec"
+
+// killGroup leaves cmd as is: cancelling it kills only the process itself.
+func killGroup(cmd *exec.Cmd) {}
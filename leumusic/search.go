@@ -0,0 +1,227 @@
+package leumusic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultSearchResults is how many search results are scored per song
+// when Options.SearchResults is zero.
+const DefaultSearchResults = 5
+
+// ErrNoCandidates is returned when a search finds nothing to download.
+var ErrNoCandidates = errors.New("no search results")
+
+// Provider is a yt-dlp search prefix.
+type Provider string
+
+const (
+	ProviderYouTube      Provider = "ytsearch"
+	ProviderYouTubeMusic Provider = "ytmsearch"
+	ProviderSoundCloud   Provider = "scsearch"
+)
+
+var providerNames = map[string]Provider{
+	"youtube":       ProviderYouTube,
+	"yt":            ProviderYouTube,
+	"youtube-music": ProviderYouTubeMusic,
+	"ytm":           ProviderYouTubeMusic,
+	"soundcloud":    ProviderSoundCloud,
+	"sc":            ProviderSoundCloud,
+}
+
+// ParseProvider accepts a provider name ("youtube", "youtube-music",
+// "soundcloud", or their short forms "yt", "ytm", "sc") or a yt-dlp search
+// prefix such as "scsearch".
+func ParseProvider(name string) (Provider, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if p, ok := providerNames[name]; ok {
+		return p, true
+	}
+	if strings.HasSuffix(name, "search") && !strings.ContainsAny(name, ": ") {
+		return Provider(name), true
+	}
+	return "", false
+}
+
+// Candidate is one search result considered for a song.
+type Candidate struct {
+	// Provider is the search that found this result, and Rank its 1-based
+	// position in that search.
+	Provider  Provider `json:"provider"`
+	Rank      int      `json:"rank"`
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	Channel   string   `json:"channel"`
+	Duration  float64  `json:"duration"`
+	ViewCount int64    `json:"view_count"`
+	Verified  bool     `json:"channel_is_verified"`
+	URL       string   `json:"url"`
+	Score     float64  `json:"score"`
+	// Rejected says why the Filter ruled this result out, if it did.
+	Rejected string `json:"rejected,omitempty"`
+}
+
+const candidateTemplate = "%(.{id,title,channel,uploader,duration,view_count,channel_is_verified,url,webpage_url})j"
+
+// unwantedVersions are words that mark a different recording than the one
+// asked for. They only count when the requested song doesn't contain them.
+var unwantedVersions = regexp.MustCompile(`\b(live|en vivo|ao vivo|dal vivo|cover|karaoke|instrumental|remix|nightcore|slowed|reverb|sped up|8d|reaction|tutorial|loop|\d+\s*hours?|\d+\s*horas?)\b`)
+
+// ScoreCandidate rates how likely a search result is the original studio
+// recording of artist - song. Higher is better; title similarity gives up
+// to 1, and channel, duration and popularity adjust it.
+func ScoreCandidate(c Candidate, artist, song string) float64 {
+	score := titleScore(NormalizeTitle(artist), NormalizeTitle(song), NormalizeTitle(c.Title))
+
+	channel := NormalizeTitle(c.Channel)
+	topic := strings.HasSuffix(channel, " topic")
+	channel = strings.TrimSuffix(channel, " topic")
+	channel = strings.TrimSuffix(channel, "vevo")
+	channel = strings.TrimSuffix(channel, " official")
+	channel = strings.TrimSpace(channel)
+	if sameName(channel, NormalizeTitle(artist)) {
+		if topic {
+			score += 0.3
+		} else {
+			score += 0.2
+		}
+	}
+	if c.Verified {
+		score += 0.05
+	}
+
+	title := foldDiacritics(strings.ToLower(c.Title))
+	wanted := foldDiacritics(strings.ToLower(song))
+	for _, word := range unwantedVersions.FindAllString(title, -1) {
+		if !strings.Contains(wanted, word) {
+			score -= 0.4
+		}
+	}
+	if strings.Contains(title, "official") && !strings.Contains(title, "live") {
+		score += 0.05
+	}
+
+	switch {
+	case c.Duration == 0:
+	case c.Duration > 20*60:
+		score -= 0.5
+	case c.Duration > 10*60:
+		score -= 0.2
+	case c.Duration < 45:
+		score -= 0.3
+	}
+
+	if c.ViewCount > 0 {
+		score += math.Min(math.Log10(float64(c.ViewCount)), 9) / 90
+	}
+	return score
+}
+
+// DurationScore adjusts the score of a result lasting found seconds for a
+// song known to last want: a few seconds off is likely the same recording,
+// a minute off likely isn't. An unknown length on either side counts 0.
+func DurationScore(found float64, want time.Duration) float64 {
+	if found <= 0 || want <= 0 {
+		return 0
+	}
+	switch off := math.Abs(found - want.Seconds()); {
+	case off <= 3:
+		return 0.2
+	case off <= 10:
+		return 0.1
+	case off > 60:
+		return -0.2
+	}
+	return 0
+}
+
+func sameName(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	return strings.ReplaceAll(a, " ", "") == strings.ReplaceAll(b, " ", "") || similarity(a, b) >= DefaultMatchThreshold
+}
+
+// searchCandidates asks yt-dlp for the top n results of query without
+// downloading them, and returns them best first with the ones the filter
+// rejects at the end. It fails if every result was rejected.
+func (d *Downloader) searchCandidates(ctx context.Context, provider Provider, t DownloadTask, n int, filter Filter, cookies bool) ([]Candidate, error) {
+	args := []string{
+		"--flat-playlist",
+		"--skip-download",
+		"--no-warnings",
+		"--ignore-errors",
+		"--socket-timeout", "30",
+		"--print", candidateTemplate,
+	}
+	target := fmt.Sprintf("%s%d:%s %s", provider, n, t.Artist, t.Song)
+	if cookies {
+		args = append(args, d.cookieSource(target).args()...)
+	}
+	args = append(args, target)
+
+	output, err := d.runYtDlp(ctx, args...)
+	if errors.Is(err, ErrToolMissing) {
+		return nil, err
+	}
+
+	var candidates []Candidate
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var entry struct {
+			Candidate
+			Uploader   string `json:"uploader"`
+			WebpageURL string `json:"webpage_url"`
+		}
+		if json.Unmarshal([]byte(line), &entry) != nil {
+			continue
+		}
+		c := entry.Candidate
+		if c.Channel == "" {
+			c.Channel = entry.Uploader
+		}
+		if c.URL == "" {
+			c.URL = entry.WebpageURL
+		}
+		if c.URL == "" && c.ID != "" && strings.HasPrefix(string(provider), "yt") {
+			c.URL = "https://www.youtube.com/watch?v=" + c.ID
+		}
+		if c.URL == "" {
+			continue
+		}
+		c.Provider = provider
+		c.Rank = len(candidates) + 1
+		c.Score = ScoreCandidate(c, t.Artist, t.Song) + DurationScore(c.Duration, t.Duration)
+		c.Rejected = filter.Reject(c, t.Song)
+		candidates = append(candidates, c)
+	}
+
+	if len(candidates) == 0 {
+		if err != nil {
+			return nil, classifyOutput(string(output), err)
+		}
+		return nil, ErrNoCandidates
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if (candidates[i].Rejected == "") != (candidates[j].Rejected == "") {
+			return candidates[i].Rejected == ""
+		}
+		return candidates[i].Score > candidates[j].Score
+	})
+	if candidates[0].Rejected != "" {
+		return candidates, fmt.Errorf("%w: all %d results were filtered out", ErrNoCandidates, len(candidates))
+	}
+	return candidates, nil
+}
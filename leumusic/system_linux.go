@@ -0,0 +1,126 @@
+package leumusic
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SystemMemory returns the memory this process may use in bytes: the
+// machine's RAM, or the cgroup limit if that is lower. 0 means unknown.
+func SystemMemory() uint64 {
+	total := meminfo("MemTotal")
+	if limit := cgroupMemoryLimit(); limit > 0 && (total == 0 || limit < total) {
+		return limit
+	}
+	return total
+}
+
+// availableMemory returns how much memory is still free for new workers,
+// or 0 if unknown.
+func availableMemory() uint64 {
+	free := meminfo("MemAvailable")
+	if limit := cgroupMemoryLimit(); limit > 0 {
+		used := readUint("/sys/fs/cgroup/memory.current")
+		if used == 0 {
+			used = readUint("/sys/fs/cgroup/memory/memory.usage_in_bytes")
+		}
+		if used < limit && (free == 0 || limit-used < free) {
+			free = limit - used
+		}
+	}
+	return free
+}
+
+// meminfo reads a field of /proc/meminfo in bytes.
+func meminfo(field string) uint64 {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || name != field {
+			continue
+		}
+		kb, _ := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(rest), " kB"), 10, 64)
+		return kb * 1024
+	}
+	return 0
+}
+
+// cgroupMemoryLimit returns the cgroup v2 or v1 memory limit, or 0.
+func cgroupMemoryLimit() uint64 {
+	if limit := readUint("/sys/fs/cgroup/memory.max"); limit > 0 {
+		return limit
+	}
+	limit := readUint("/sys/fs/cgroup/memory/memory.limit_in_bytes")
+	// cgroup v1 reports "no limit" as a huge page-aligned number.
+	if limit >= 1<<62 {
+		return 0
+	}
+	return limit
+}
+
+// cgroupCPUs returns the CPUs allowed by the cgroup quota, or 0.
+func cgroupCPUs() float64 {
+	var quota, period float64
+	if data, err := os.ReadFile("/sys/fs/cgroup/cpu.max"); err == nil {
+		fields := strings.Fields(string(data))
+		if len(fields) == 2 && fields[0] != "max" {
+			quota, _ = strconv.ParseFloat(fields[0], 64)
+			period, _ = strconv.ParseFloat(fields[1], 64)
+		}
+	} else {
+		quota = float64(readInt("/sys/fs/cgroup/cpu/cpu.cfs_quota_us"))
+		period = float64(readInt("/sys/fs/cgroup/cpu/cpu.cfs_period_us"))
+	}
+	if quota <= 0 || period <= 0 {
+		return 0
+	}
+	return quota / period
+}
+
+// cpuTimes returns the busy and total CPU time since boot, in clock ticks.
+func cpuTimes() (busy, total uint64, ok bool) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return 0, 0, false
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	fields := strings.Fields(line)
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0, 0, false
+	}
+	for i, field := range fields[1:] {
+		v, _ := strconv.ParseUint(field, 10, 64)
+		total += v
+		// idle and iowait
+		if i != 3 && i != 4 {
+			busy += v
+		}
+	}
+	return busy, total, true
+}
+
+func readUint(path string) uint64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	v, _ := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	return v
+}
+
+func readInt(path string) int64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	v, _ := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	return v
+}
@@ -0,0 +1,37 @@
+//go:build !linux && !windows
+
+package leumusic
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// SystemMemory returns the machine's RAM in bytes, as reported by sysctl
+// on macOS and the BSDs, or 0 if unknown.
+func SystemMemory() uint64 {
+	for _, name := range []string{"hw.memsize", "hw.physmem64", "hw.physmem"} {
+		output, err := exec.Command("sysctl", "-n", name).Output()
+		if err != nil {
+			continue
+		}
+		if v, err := strconv.ParseUint(strings.TrimSpace(string(output)), 10, 64); err == nil && v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// availableMemory is unknown here, so it never holds workers back.
+func availableMemory() uint64 {
+	return 0
+}
+
+func cgroupCPUs() float64 {
+	return 0
+}
+
+func cpuTimes() (busy, total uint64, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,63 @@
+package leumusic
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                 = syscall.NewLazyDLL("kernel32.dll")
+	procGlobalMemoryStatusEx = kernel32.NewProc("GlobalMemoryStatusEx")
+	procGetSystemTimes       = kernel32.NewProc("GetSystemTimes")
+)
+
+type memoryStatusEx struct {
+	length               uint32
+	memoryLoad           uint32
+	totalPhys            uint64
+	availPhys            uint64
+	totalPageFile        uint64
+	availPageFile        uint64
+	totalVirtual         uint64
+	availVirtual         uint64
+	availExtendedVirtual uint64
+}
+
+func memoryStatus() (memoryStatusEx, bool) {
+	status := memoryStatusEx{}
+	status.length = uint32(unsafe.Sizeof(status))
+	ok, _, _ := procGlobalMemoryStatusEx.Call(uintptr(unsafe.Pointer(&status)))
+	return status, ok != 0
+}
+
+// SystemMemory returns the machine's RAM in bytes, or 0 if unknown.
+func SystemMemory() uint64 {
+	status, _ := memoryStatus()
+	return status.totalPhys
+}
+
+// availableMemory returns how much memory is still free for new workers,
+// or 0 if unknown.
+func availableMemory() uint64 {
+	status, _ := memoryStatus()
+	return status.availPhys
+}
+
+func cgroupCPUs() float64 {
+	return 0
+}
+
+// cpuTimes returns the busy and total CPU time since boot, in 100ns units.
+func cpuTimes() (busy, total uint64, ok bool) {
+	var idle, kernel, user syscall.Filetime
+	r, _, _ := procGetSystemTimes.Call(uintptr(unsafe.Pointer(&idle)), uintptr(unsafe.Pointer(&kernel)), uintptr(unsafe.Pointer(&user)))
+	if r == 0 {
+		return 0, 0, false
+	}
+	ticks := func(f syscall.Filetime) uint64 {
+		return uint64(f.HighDateTime)<<32 | uint64(f.LowDateTime)
+	}
+	// Kernel time includes idle time.
+	total = ticks(kernel) + ticks(user)
+	return total - ticks(idle), total, true
+}
@@ -0,0 +1,312 @@
+package leumusic
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DownloadTask is one song to search for, or one URL to download. A task
+// with a song and a URL downloads that URL as the song.
+type DownloadTask struct {
+	Artist string `json:"artist,omitempty"`
+	Song   string `json:"song,omitempty"`
+	URL    string `json:"url,omitempty"`
+	// Filter, if set, is merged over the Downloader's filter for this song.
+	Filter *Filter `json:"filter,omitempty"`
+	// Format is what the song is saved as; empty means MP3.
+	Format AudioFormat `json:"format,omitempty"`
+	// Album, if set, replaces the album tag the video came with.
+	Album string `json:"album,omitempty"`
+	// NoCookies never passes browser cookies to yt-dlp for this task, not
+	// even to get past a bot check.
+	NoCookies bool `json:"no_cookies,omitempty"`
+	// Playlist, if set, saves the song in the playlist's folder instead of
+	// the artist's.
+	Playlist string `json:"playlist,omitempty"`
+	// Duration is how long the song is, when known from where it was
+	// imported. Search results close to it score higher.
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// Key returns the "artist - song" form used by ledgers and song files, or
+// the URL of a task without a song.
+func (t DownloadTask) Key() string {
+	if t.Artist == "" && t.Song == "" {
+		return t.URL
+	}
+	return fmt.Sprintf("%s - %s", t.Artist, t.Song)
+}
+
+// Line returns the task as a songs file line, options included, so that
+// ParseTask reads it back.
+func (t DownloadTask) Line() string {
+	var b strings.Builder
+	b.WriteString(t.Key())
+	if t.URL != "" && t.Song != "" {
+		b.WriteString(" @ " + t.URL)
+	}
+	if t.Format != "" {
+		fmt.Fprintf(&b, " [%s]", t.Format)
+	}
+	if t.Album != "" {
+		fmt.Fprintf(&b, " [album: %s]", t.Album)
+	}
+	if t.NoCookies {
+		b.WriteString(" [no-cookies]")
+	}
+	if t.Playlist != "" {
+		fmt.Fprintf(&b, " [playlist: %s]", t.Playlist)
+	}
+	if t.Duration > 0 {
+		fmt.Fprintf(&b, " [duration: %s]", t.Duration)
+	}
+	if f := t.Filter; f != nil {
+		if len(f.Include) > 0 {
+			fmt.Fprintf(&b, " [include: %s]", strings.Join(f.Include, ", "))
+		}
+		if len(f.Exclude) > 0 {
+			fmt.Fprintf(&b, " [exclude: %s]", strings.Join(f.Exclude, ", "))
+		}
+		if f.MinDuration > 0 {
+			fmt.Fprintf(&b, " [min-duration: %s]", f.MinDuration)
+		}
+		if f.MaxDuration > 0 {
+			fmt.Fprintf(&b, " [max-duration: %s]", f.MaxDuration)
+		}
+	}
+	return b.String()
+}
+
+// AudioFormat is the kind of file a song is saved as.
+type AudioFormat string
+
+const (
+	FormatMP3  AudioFormat = "mp3"
+	FormatFLAC AudioFormat = "flac"
+)
+
+func (t DownloadTask) format() AudioFormat {
+	if t.Format == "" {
+		return FormatMP3
+	}
+	return t.Format
+}
+
+// PlaylistInfo names a playlist and where to fetch it.
+type PlaylistInfo struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+var lineSeparators = []string{" - ", " | ", " :: ", " -> "}
+
+var lineOption = regexp.MustCompile(`\[([^\[\]]*)\]\s*$`)
+
+// LineError is a songs file line that doesn't follow the grammar. Column
+// counts characters from 1.
+type LineError struct {
+	Column  int
+	Message string
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Message)
+}
+
+func lineError(line string, offset int, format string, args ...any) *LineError {
+	return &LineError{Column: utf8.RuneCountInString(line[:offset]) + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+// cutLineOptions reads the trailing options of line into task, such as
+// "[flac]" or "[max-duration: 6m]", and returns where they start. Brackets
+// that aren't options, such as "[Remastered]", are left as part of the
+// song.
+func cutLineOptions(line string, task *DownloadTask) (int, error) {
+	end := len(line)
+	for {
+		m := lineOption.FindStringSubmatchIndex(line[:end])
+		if m == nil {
+			return end, nil
+		}
+		inner := line[m[2]:m[3]]
+		key, value, hasValue := strings.Cut(inner, ":")
+		key = strings.ToLower(strings.TrimSpace(key))
+		valueAt := m[2] + len(inner) - len(strings.TrimLeft(value, " \t"))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "flac", "mp3", "no-cookies":
+			if hasValue {
+				return 0, lineError(line, valueAt, "[%s] takes no value", key)
+			}
+		case "album", "playlist", "duration", "include", "exclude", "min-duration", "max-duration":
+			if value == "" {
+				return 0, lineError(line, m[0], "[%s] needs a value, as in [%s: ...]", key, key)
+			}
+		default:
+			return end, nil
+		}
+
+		switch key {
+		case "flac", "mp3":
+			if task.Format != "" && task.Format != AudioFormat(key) {
+				return 0, lineError(line, m[0], "[%s] conflicts with [%s]", key, task.Format)
+			}
+			task.Format = AudioFormat(key)
+		case "no-cookies":
+			task.NoCookies = true
+		case "album":
+			task.Album = value
+		case "playlist":
+			task.Playlist = value
+		case "duration":
+			d, err := ParseDuration(value)
+			if err != nil {
+				return 0, lineError(line, valueAt, "invalid duration %q", value)
+			}
+			task.Duration = d
+		default:
+			if task.Filter == nil {
+				task.Filter = &Filter{}
+			}
+			filter := task.Filter
+			switch key {
+			case "include":
+				filter.Include = append(filter.Include, splitWords(value)...)
+			case "exclude":
+				filter.Exclude = append(filter.Exclude, splitWords(value)...)
+			case "min-duration", "max-duration":
+				d, err := ParseDuration(value)
+				if err != nil {
+					return 0, lineError(line, valueAt, "invalid duration %q", value)
+				}
+				if key == "min-duration" {
+					filter.MinDuration = d
+				} else {
+					filter.MaxDuration = d
+				}
+			}
+		}
+		end = m[0]
+	}
+}
+
+func isURL(s string) bool {
+	lower := strings.ToLower(s)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// checkURL reports what is wrong with the URL at offset in line, if
+// anything.
+func checkURL(line string, offset int, target string) error {
+	if i := strings.IndexAny(target, " \t"); i >= 0 {
+		rest := strings.TrimLeft(target[i:], " \t")
+		return lineError(line, offset+len(target)-len(rest), "unexpected text after the URL")
+	}
+	if u, err := url.Parse(target); err != nil || u.Host == "" {
+		return lineError(line, offset, "invalid URL %q", target)
+	}
+	return nil
+}
+
+func splitWords(value string) []string {
+	var words []string
+	for _, word := range strings.Split(value, ",") {
+		if word = strings.TrimSpace(word); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// ParseDuration accepts Go durations ("90s", "6m") and plain seconds.
+func ParseDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err == nil && d < 0 {
+		err = fmt.Errorf("negative duration %q", value)
+	}
+	return d, err
+}
+
+// ParseTask parses one songs file line, which is one of
+//
+//	artist - song
+//	artist - song @ https://...
+//	https://...
+//
+// with " | ", " :: " or " -> " also separating artist and song, followed
+// by any of these options: [flac], [mp3], [album: name], [no-cookies],
+// [playlist: name], [duration: 3m45s], [include: words], [exclude: words],
+// [min-duration: 60s] and [max-duration: 6m]. The URL after "@" pins the
+// video to download for the song; " @ " followed by anything else is an
+// error. Errors are *LineError.
+func ParseTask(line string) (*DownloadTask, error) {
+	task := &DownloadTask{}
+	end, err := cutLineOptions(line, task)
+	if err != nil {
+		return nil, err
+	}
+	start := len(line[:end]) - len(strings.TrimLeft(line[:end], " \t"))
+	body := strings.TrimRight(line[start:end], " \t")
+
+	if isURL(body) {
+		if err := checkURL(line, start, body); err != nil {
+			return nil, err
+		}
+		task.URL = body
+		return task, nil
+	}
+
+	if i := strings.LastIndex(body, " @ "); i >= 0 {
+		target := strings.TrimLeft(body[i+3:], " \t")
+		at := start + len(body) - len(target)
+		if !isURL(target) {
+			return nil, lineError(line, at, "expected a URL after \"@\", found %q", target)
+		}
+		if err := checkURL(line, at, target); err != nil {
+			return nil, err
+		}
+		task.URL = target
+		body = strings.TrimRight(body[:i], " \t")
+	}
+
+	// Padded, so "- song" and "artist -" still find the separator.
+	padded := " " + body + " "
+	for _, sep := range lineSeparators {
+		i := strings.Index(padded, sep)
+		if i < 0 {
+			continue
+		}
+		// The separator's mark is padded[i+1], which is body[i].
+		mark := strings.TrimSpace(sep)
+		at := start + i
+		artist, song := strings.TrimSpace(padded[:i]), strings.TrimSpace(padded[i+len(sep):])
+		switch {
+		case artist == "":
+			return nil, lineError(line, at, "missing the artist before %q", mark)
+		case song == "":
+			return nil, lineError(line, at, "missing the song after %q", mark)
+		}
+		task.Artist, task.Song = artist, song
+		return task, nil
+	}
+	if body == "" {
+		return nil, lineError(line, start, "expected \"artist - song\" or a URL")
+	}
+	return nil, lineError(line, start, "expected \"artist - song\" or a URL, found %q", body)
+}
+
+// ParseLine is ParseTask for callers that don't need to know what is
+// wrong: it returns nil when the line does not match.
+func ParseLine(line string) *DownloadTask {
+	task, _ := ParseTask(line)
+	return task
+}
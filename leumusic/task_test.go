@@ -0,0 +1,124 @@
+package leumusic
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTask(t *testing.T) {
+	tests := []struct {
+		line string
+		want DownloadTask
+	}{
+		{"Queen - Bohemian Rhapsody", DownloadTask{Artist: "Queen", Song: "Bohemian Rhapsody"}},
+		{"  Queen | Bohemian Rhapsody  ", DownloadTask{Artist: "Queen", Song: "Bohemian Rhapsody"}},
+		{"Queen :: Bohemian Rhapsody", DownloadTask{Artist: "Queen", Song: "Bohemian Rhapsody"}},
+		{"Queen -> Bohemian Rhapsody", DownloadTask{Artist: "Queen", Song: "Bohemian Rhapsody"}},
+		{"AC-DC - Back in Black", DownloadTask{Artist: "AC-DC", Song: "Back in Black"}},
+		{"Daft Punk - Harder - Better", DownloadTask{Artist: "Daft Punk", Song: "Harder - Better"}},
+		{"https://www.youtube.com/watch?v=abc", DownloadTask{URL: "https://www.youtube.com/watch?v=abc"}},
+		{"Queen - Innuendo @ https://youtu.be/abc", DownloadTask{Artist: "Queen", Song: "Innuendo", URL: "https://youtu.be/abc"}},
+		{"Queen - Innuendo [Remastered]", DownloadTask{Artist: "Queen", Song: "Innuendo [Remastered]"}},
+		{"Queen - Innuendo [flac]", DownloadTask{Artist: "Queen", Song: "Innuendo", Format: FormatFLAC}},
+		{"Queen - Innuendo [MP3] [no-cookies]", DownloadTask{Artist: "Queen", Song: "Innuendo", Format: FormatMP3, NoCookies: true}},
+		{"Queen - Innuendo [album: Innuendo (Deluxe)] [playlist: Road Trip]", DownloadTask{Artist: "Queen", Song: "Innuendo", Album: "Innuendo (Deluxe)", Playlist: "Road Trip"}},
+		{"Queen - Innuendo [duration: 6m31s]", DownloadTask{Artist: "Queen", Song: "Innuendo", Duration: 391 * time.Second}},
+		{"Queen - Innuendo [duration: 391]", DownloadTask{Artist: "Queen", Song: "Innuendo", Duration: 391 * time.Second}},
+		{
+			"Queen - Innuendo [include: official, audio] [exclude: live] [min-duration: 60s] [max-duration: 10m]",
+			DownloadTask{Artist: "Queen", Song: "Innuendo", Filter: &Filter{Include: []string{"official", "audio"}, Exclude: []string{"live"}, MinDuration: time.Minute, MaxDuration: 10 * time.Minute}},
+		},
+		{"https://www.youtube.com/playlist?list=PL1 [playlist: Trip] [flac]", DownloadTask{URL: "https://www.youtube.com/playlist?list=PL1", Playlist: "Trip", Format: FormatFLAC}},
+	}
+	for _, tt := range tests {
+		got, err := ParseTask(tt.line)
+		if err != nil {
+			t.Errorf("ParseTask(%q) error: %v", tt.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("ParseTask(%q) = %+v, want %+v", tt.line, *got, tt.want)
+		}
+	}
+}
+
+func TestParseTaskErrors(t *testing.T) {
+	tests := []struct {
+		line    string
+		column  int
+		message string
+	}{
+		{"", 1, `expected "artist - song" or a URL`},
+		{"Queen", 1, `found "Queen"`},
+		{"- Innuendo", 1, `missing the artist before "-"`},
+		{"Queen -", 7, `missing the song after "-"`},
+		{"  Queen ::", 9, `missing the song after "::"`},
+		{"Beyoncé -", 9, `missing the song after "-"`},
+		{"Queen - Innuendo @ notaurl", 20, `expected a URL after "@", found "notaurl"`},
+		{"Queen - Innuendo @ https://youtu.be/abc extra", 41, "unexpected text after the URL"},
+		{"Queen - Innuendo @ https://", 20, "invalid URL"},
+		{"https://youtu.be/abc oops", 22, "unexpected text after the URL"},
+		{"Queen - Innuendo [flac: yes]", 25, "[flac] takes no value"},
+		{"Queen - Innuendo [album: ]", 18, "[album] needs a value"},
+		{"Queen - Innuendo [mp3] [flac]", 18, "[mp3] conflicts with [flac]"},
+		{"Queen - Innuendo [duration: soon]", 29, `invalid duration "soon"`},
+		{"Queen - Innuendo [max-duration: -1m]", 33, `invalid duration "-1m"`},
+	}
+	for _, tt := range tests {
+		_, err := ParseTask(tt.line)
+		var le *LineError
+		if !errors.As(err, &le) {
+			t.Errorf("ParseTask(%q) error = %v, want a *LineError", tt.line, err)
+			continue
+		}
+		if le.Column != tt.column || !strings.Contains(le.Message, tt.message) {
+			t.Errorf("ParseTask(%q) error = column %d: %s, want column %d: %s", tt.line, le.Column, le.Message, tt.column, tt.message)
+		}
+	}
+}
+
+func TestTaskLineRoundTrip(t *testing.T) {
+	tasks := []DownloadTask{
+		{Artist: "Queen", Song: "Innuendo"},
+		{URL: "https://www.youtube.com/watch?v=abc"},
+		{Artist: "Queen", Song: "Innuendo [Remastered]", URL: "https://youtu.be/abc"},
+		{Artist: "ABBA", Song: "Waterloo", Format: FormatFLAC, Album: "Gold", NoCookies: true, Playlist: "Road Trip", Duration: 165 * time.Second},
+		{Artist: "Queen", Song: "Innuendo", Filter: &Filter{Include: []string{"audio"}, Exclude: []string{"live", "remix"}, MinDuration: time.Minute, MaxDuration: 10 * time.Minute}},
+		{URL: "https://www.youtube.com/playlist?list=PL1", Playlist: "Trip", Format: FormatMP3},
+	}
+	for _, want := range tasks {
+		line := want.Line()
+		got, err := ParseTask(line)
+		if err != nil {
+			t.Errorf("ParseTask(%q) error: %v", line, err)
+			continue
+		}
+		if !reflect.DeepEqual(*got, want) {
+			t.Errorf("ParseTask(%q) = %+v, want %+v", line, *got, want)
+		}
+	}
+}
+
+func TestTaskJSONRoundTrip(t *testing.T) {
+	want := DownloadTask{Artist: "Queen", Song: "Innuendo", Duration: 391 * time.Second, Filter: &Filter{Include: []string{"audio"}, Exclude: []string{"live"}, MinDuration: time.Minute, MaxDuration: 10 * time.Minute}}
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"include":`, `"exclude":`, `"min_duration":60000000000`, `"max_duration":600000000000`, `"duration":391000000000`} {
+		if !strings.Contains(string(data), key) {
+			t.Errorf("Marshal = %s, missing %s", data, key)
+		}
+	}
+	var got DownloadTask
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+}
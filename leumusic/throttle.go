@@ -0,0 +1,141 @@
+package leumusic
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Throttle spaces out the yt-dlp runs of all workers, so a big batch
+// doesn't get the IP throttled, and pauses every worker when the sites
+// start blocking anyway.
+type Throttle struct {
+	// Rate is how many searches and downloads may start per second, with
+	// up to Burst of them at once. A negative Rate disables the limit.
+	Rate  float64
+	Burst int
+	// Jitter adds a random wait of up to this much before every run.
+	Jitter time.Duration
+	// BreakAfter is how many rate-limit or bot-check errors in a row pause
+	// all workers for Cooldown. 0 never pauses.
+	BreakAfter int
+	Cooldown   time.Duration
+}
+
+// DefaultThrottle returns the throttle used when Options.Throttle is nil.
+func DefaultThrottle() Throttle {
+	return Throttle{
+		Rate:       2,
+		Burst:      4,
+		Jitter:     500 * time.Millisecond,
+		BreakAfter: 5,
+		Cooldown:   2 * time.Minute,
+	}
+}
+
+// limiter is a token bucket shared by all workers, with a circuit breaker
+// that holds every request back until pausedUntil.
+type limiter struct {
+	cfg Throttle
+
+	mu          sync.Mutex
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+	// pause is the length of a pause nobody has reported the end of yet.
+	pause  time.Duration
+	streak int
+}
+
+func newLimiter(cfg Throttle) *limiter {
+	if cfg.Burst < 1 {
+		cfg.Burst = 1
+	}
+	return &limiter{cfg: cfg, tokens: float64(cfg.Burst)}
+}
+
+// reserve takes a token and returns how long to wait before using it.
+func (l *limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	var wait time.Duration
+	if l.cfg.Rate > 0 {
+		if !l.last.IsZero() {
+			l.tokens += now.Sub(l.last).Seconds() * l.cfg.Rate
+			l.tokens = min(l.tokens, float64(l.cfg.Burst))
+		}
+		l.last = now
+		l.tokens--
+		if l.tokens < 0 {
+			wait = time.Duration(-l.tokens / l.cfg.Rate * float64(time.Second))
+		}
+	}
+	if paused := l.pausedUntil.Sub(now); paused > wait {
+		wait = paused
+	}
+	if l.cfg.Jitter > 0 {
+		wait += rand.N(l.cfg.Jitter)
+	}
+	return wait
+}
+
+// wait blocks until the caller may start a request, or ctx is done. The
+// first caller let through after a pause gets its length, the others get 0.
+func (l *limiter) wait(ctx context.Context) time.Duration {
+	for {
+		if wait := l.reserve(); wait > 0 && !sleep(ctx, wait) {
+			return 0
+		}
+
+		// The breaker may have opened while this request was waiting.
+		l.mu.Lock()
+		paused := time.Until(l.pausedUntil) > 0
+		var resumed time.Duration
+		if !paused {
+			resumed, l.pause = l.pause, 0
+		}
+		l.mu.Unlock()
+		if !paused {
+			return resumed
+		}
+	}
+}
+
+// report records the outcome of a request. It returns how many blocking
+// errors came in a row when that opened the breaker, or 0.
+func (l *limiter) report(err error) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch ErrorKindOf(err) {
+	case ErrorRateLimited, ErrorBotCheck:
+		l.streak++
+	default:
+		l.streak = 0
+		return 0
+	}
+	if l.cfg.BreakAfter <= 0 || l.streak < l.cfg.BreakAfter || time.Now().Before(l.pausedUntil) {
+		return 0
+	}
+	streak := l.streak
+	l.streak = 0
+	l.pausedUntil = time.Now().Add(l.cfg.Cooldown)
+	l.pause = l.cfg.Cooldown
+	return streak
+}
+
+// sleep waits for d, or until ctx is done. It reports whether all of d
+// passed.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
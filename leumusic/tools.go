@@ -0,0 +1,195 @@
+package leumusic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrToolMissing is returned when yt-dlp or ffmpeg cannot be found.
+var ErrToolMissing = errors.New("tool not found")
+
+// ToolInfo describes a resolved external tool.
+type ToolInfo struct {
+	Name    string
+	Path    string
+	Version string
+}
+
+// RequiredTools lists the external programs a Downloader depends on and the
+// argument that makes each one print its version.
+var RequiredTools = []struct {
+	Name       string
+	VersionArg string
+}{
+	{"yt-dlp", "--version"},
+	{"ffmpeg", "-version"},
+}
+
+// Toolbox finds and runs external tools without going through a shell.
+// The zero value is ready to use.
+type Toolbox struct {
+	mu       sync.Mutex
+	paths    map[string]string
+	resolved map[string]string
+}
+
+// NewToolbox returns a Toolbox that prefers the given absolute paths,
+// keyed by tool name. Empty paths fall back to automatic discovery.
+func NewToolbox(paths map[string]string) *Toolbox {
+	t := &Toolbox{}
+	for name, path := range paths {
+		t.SetPath(name, path)
+	}
+	return t
+}
+
+func executableName(name string) string {
+	if runtime.GOOS == "windows" && filepath.Ext(name) == "" {
+		return name + ".exe"
+	}
+	return name
+}
+
+// SetPath configures the path for a tool. An empty path means automatic
+// discovery.
+func (t *Toolbox) SetPath(name, path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.paths == nil {
+		t.paths = map[string]string{}
+	}
+	t.paths[name] = path
+	delete(t.resolved, name)
+}
+
+// ConfiguredPath returns the path set with SetPath, if any.
+func (t *Toolbox) ConfiguredPath(name string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.paths[name]
+}
+
+// Resolve looks for a tool in this order: configured absolute path,
+// next to the executable, then PATH.
+func (t *Toolbox) Resolve(name string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if path, ok := t.resolved[name]; ok {
+		return path, nil
+	}
+	if t.resolved == nil {
+		t.resolved = map[string]string{}
+	}
+
+	var candidates []string
+	if configured := t.paths[name]; configured != "" {
+		candidates = append(candidates, configured)
+	}
+	if exe, err := os.Executable(); err == nil {
+		candidates = append(candidates, filepath.Join(filepath.Dir(exe), executableName(name)))
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			t.resolved[name] = candidate
+			return candidate, nil
+		}
+	}
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, ErrToolMissing)
+	}
+	t.resolved[name] = path
+	return path, nil
+}
+
+// Command prepares an exec.Cmd for the named tool.
+func (t *Toolbox) Command(name string, args ...string) (*exec.Cmd, error) {
+	path, err := t.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(path, args...), nil
+}
+
+// Run executes the named tool and returns its combined output.
+func (t *Toolbox) Run(name string, args ...string) ([]byte, error) {
+	return t.RunContext(context.Background(), name, args...)
+}
+
+// killWait is how long a killed tool may keep its output open before
+// RunContext stops waiting for it.
+const killWait = 5 * time.Second
+
+// RunContext is like Run, but once ctx is done the tool is killed, along
+// with any process it started, and the cause of ctx is returned.
+func (t *Toolbox) RunContext(ctx context.Context, name string, args ...string) ([]byte, error) {
+	path, err := t.Resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	if ctx.Done() != nil {
+		killGroup(cmd)
+		cmd.WaitDelay = killWait
+	}
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return output, context.Cause(ctx)
+	}
+	return output, err
+}
+
+// Inspect resolves a tool and asks it for its version.
+func (t *Toolbox) Inspect(name, versionArg string) (ToolInfo, error) {
+	info := ToolInfo{Name: name}
+
+	path, err := t.Resolve(name)
+	if err != nil {
+		return info, err
+	}
+	info.Path = path
+
+	output, err := t.Run(name, versionArg)
+	if err != nil {
+		return info, err
+	}
+	info.Version = parseToolVersion(name, string(output))
+	return info, nil
+}
+
+func parseToolVersion(name, output string) string {
+	line := strings.TrimSpace(strings.SplitN(output, "\n", 2)[0])
+	if prefix := name + " version "; strings.HasPrefix(line, prefix) {
+		line = strings.TrimPrefix(line, prefix)
+		if i := strings.Index(line, " "); i > 0 {
+			line = line[:i]
+		}
+	}
+	return line
+}
+
+// InstallHints returns install commands for the required tools on the
+// current operating system.
+func InstallHints() []string {
+	switch runtime.GOOS {
+	case "windows":
+		return []string{"yt-dlp: pip install yt-dlp", "ffmpeg: winget install Gyan.FFmpeg"}
+	case "darwin":
+		return []string{"yt-dlp: brew install yt-dlp", "ffmpeg: brew install ffmpeg"}
+	default:
+		return []string{"yt-dlp: pip install yt-dlp", "ffmpeg: sudo apt install ffmpeg"}
+	}
+}
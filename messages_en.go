@@ -0,0 +1,256 @@
+package main
+
+var catalogEN = catalog{
+	"file.songs":        "songs.txt",
+	"file.legacyLedger": "downloaded.txt",
+
+	"config.problem": "⚠️  Configuration problem: %v",
+
+	"menu.options":     "\nOptions:",
+	"menu.download":    "1. Download from %s file",
+	"menu.manual":      "2. Add songs manually",
+	"menu.cookies":     "3. Use cookies ( %v )",
+	"menu.settings":    "4. Settings",
+	"menu.check":       "5. Check tools",
+	"menu.tree":        "6. Show folder structure",
+	"menu.list":        "7. View downloaded songs",
+	"menu.resume":      "8. Resume last session (%d left)",
+	"menu.retry":       "13. Retry failed songs (%d)",
+	"menu.sync":        "14. Sync playlists (%d)",
+	"menu.exit":        "9. Exit",
+	"menu.performance": "-------------------- Performance --------------------",
+	"menu.recommended": "🔧 Recommended workers for your PC: %d",
+	"menu.turbo":       "10. Turbo Mode ( %v )",
+	"menu.quality":     "11. Quality Mode ( %v )",
+	"menu.reset":       "12. Reset to Default Settings",
+	"menu.select":      "Select: ",
+	"menu.invalid":     "Invalid option",
+
+	"settings.title":               "\n⚙️  Settings",
+	"settings.cookies":             "1. Use cookies ( %v )",
+	"settings.turbo":               "2. Turbo Mode ( %v )",
+	"settings.quality":             "3. Quality Mode ( %v )",
+	"settings.workers":             "4. Workers (%s)",
+	"settings.ytdlp":               "5. yt-dlp path (%s)",
+	"settings.ffmpeg":              "6. ffmpeg path (%s)",
+	"settings.language":            "7. Language (%s)",
+	"settings.match":               "8. Duplicate match threshold (%s)",
+	"settings.autoCookies":         "9. Retry blocked downloads with cookies ( %v )",
+	"settings.cookieSource":        "10. Cookie source (%s)",
+	"settings.back":                "0. Back",
+	"settings.workersPrompt":       "Workers (0 = auto): ",
+	"settings.invalidNumber":       "❌ Invalid number",
+	"settings.ytdlpPrompt":         "yt-dlp path (empty = auto): ",
+	"settings.ffmpegPrompt":        "ffmpeg path (empty = auto): ",
+	"settings.languagePrompt":      "Language (%s, empty = system): ",
+	"settings.matchPrompt":         "Match threshold (0-1, 0 = default %.2f): ",
+	"settings.cookieSourcePrompt":  "Cookie source (%s, browser:profile, browser:profile::container, cookies.txt path or none; empty = default): ",
+	"settings.invalidCookieSource": "❌ Invalid cookie source: %v",
+	"settings.unknownLanguage":     "❌ Unknown language: %s",
+	"settings.saveFailed":          "❌ Could not save settings: %v",
+	"settings.saved":               "💾 Settings saved to %s",
+
+	"cookies.state": "🛡️  Use cookies: %v",
+	"cookies.on":    "💡 Will try to use browser cookies to avoid blocks",
+	"cookies.off":   "💡 Cookies deactivated (may have more blocks)",
+
+	"turbo.qualityOff": "⚠️  Quality Mode automatically disabled when enabling Turbo Mode",
+	"turbo.on":         "🚀 Turbo Mode ACTIVATED (workers: %d, quality: low)",
+	"turbo.onHint":     "💡 Using lowest quality for maximum speed",
+	"turbo.off":        "🐢 Turbo Mode DEACTIVATED (quality: normal)",
+	"quality.turboOff": "⚠️  Turbo Mode automatically disabled when enabling Quality Mode",
+	"quality.on":       "🎵 Quality Mode ACTIVATED (workers: %d, quality: high)",
+	"quality.onHint":   "💡 Using highest quality for best audio",
+	"quality.off":      "🐢 Quality Mode DEACTIVATED (quality: normal)",
+	"modes.reset":      "⚙️  All modes deactivated. Returning to normal configuration.",
+
+	"tools.checking":      "\n🔍 Checking required tools...",
+	"tools.missing":       "❌ %s: NOT INSTALLED",
+	"tools.install":       "\n⚠️  Install missing tools:",
+	"tools.placeHint":     "💡 Or place the binaries next to leumusic, or set LEUMUSIC_YTDLP / LEUMUSIC_FFMPEG",
+	"tools.cookies.one":   "🍪 Cookies (%s): %d cookie for %s",
+	"tools.cookies.other": "🍪 Cookies (%s): %d cookies for %s",
+	"tools.cookiesEmpty":  "⚠️  Cookies (%s): none for %s, sign in to it in that browser",
+	"tools.cookiesError":  "⚠️  Cookies (%s) can't be read: %v",
+
+	"file.notFound":        "❌ %s file not found",
+	"file.creatingExample": "📝 Creating example file...",
+
+	"songs.alreadyDownloaded": "⏭️  Already downloaded: %s",
+	"songs.duplicate":         "⏭️  Already in the list: %s (as %s)",
+	"songs.otherPlaylist":     "🔗 Also in the playlist %s: %s (downloaded once)",
+	"songs.parked.one":        "⏭️  Parked: %s failed %d time in a row (\"leumusic retry --parked\" tries it again)",
+	"songs.parked.other":      "⏭️  Parked: %s failed %d times in a row (\"leumusic retry --parked\" tries it again)",
+	"songs.invalidLine":       "⚠️  Line %d, column %d: %s\n   %s\n   %s",
+	"songs.noneNew":           "🎯 No new songs to download. Skipped: %d",
+	"songs.found.one":         "🎶 Found %d new song (%d skipped)",
+	"songs.found.other":       "🎶 Found %d new songs (%d skipped)",
+	"import.read.one":         "📥 Read %d song from %s",
+	"import.read.other":       "📥 Read %d songs from %s",
+	"import.readError":        "❌ Could not read the song list: %v",
+
+	"manual.intro":      "\n📝 Enter songs (format: artist - song)",
+	"manual.example":    "Example: pearl jam - even flow",
+	"manual.finish":     "Type '%s' to finish",
+	"manual.finishWord": "fin",
+	"manual.added":      "✅ Added: %s",
+	"manual.invalid":    "❌ Invalid format at column %d: %s. Use: artist - song, a URL, or artist - song @ URL",
+
+	"download.starting.one":     "\n🚀 Starting TURBO download of %d song...",
+	"download.starting.other":   "\n🚀 Starting TURBO download of %d songs...",
+	"download.workers":          "⚡ Using %d concurrent workers",
+	"download.workersAdaptive":  "⚡ Starting with %d concurrent workers, adjusted as it goes (up to %d)",
+	"download.progress":         "\r📊 Progress: %.1f%% (%d/%d) - ✅%d ❌%d - ⚡%d - 🔎%d(+%d) ⬇️%d(+%d) 🎛️%d(+%d)",
+	"download.completed":        "\n\n🎊 Downloads completed in %v!",
+	"download.success":          "✅ Success: %d",
+	"download.failed":           "❌ Failed: %d",
+	"download.pauses.one":       "⏸️  Downloads were paused %d time because of blocking",
+	"download.pauses.other":     "⏸️  Downloads were paused %d times because of blocking",
+	"download.stopping":         "\n🛑 Stopping: yt-dlp and ffmpeg are being closed (Ctrl+C again to quit now)",
+	"download.started":          "   ▶️  %d. %s",
+	"download.cancelHint":       "💡 Type a song's number and press Enter to cancel it",
+	"download.cancelled":        "   ✋ Cancelled: %s",
+	"download.cancelUnknown":    "   ❓ No song %s is downloading or waiting",
+	"download.cancels.one":      "✋ %d song cancelled",
+	"download.cancels.other":    "✋ %d songs cancelled",
+	"download.runTimeout":       "\n⏱️  run_timeout (%s) reached: stopping yt-dlp and ffmpeg",
+	"download.registered":       "📝 Registered in: %s",
+	"download.structure":        "\n📁 Current folder structure:",
+	"download.existsOnDisk":     "   ⏭️  Already exists on disk: %s",
+	"download.error":            "   🔥 Error: %s",
+	"download.failedTask":       "   🔥 %s: %s (%v)",
+	"download.retrying":         "   🔁 %s: %s, retrying in %v (attempt %d)",
+	"download.cookiesEscalated": "   🍪 %s: %s, retrying with browser cookies",
+	"download.cookiesSticky":    "🍪 Many downloads are being blocked, using browser cookies for the rest of the session",
+	"download.paused":           "   ⏸️  %d blocked requests in a row (%s), pausing all downloads for %v",
+	"download.resumed":          "   ▶️  Resuming downloads after a %v pause",
+
+	"reason.unknown":        "unknown error",
+	"reason.bot-check":      "bot check",
+	"reason.rate-limited":   "rate limited",
+	"reason.geo-blocked":    "blocked in your country",
+	"reason.age-restricted": "age restricted",
+	"reason.unavailable":    "video unavailable",
+	"reason.network":        "network error",
+	"reason.postprocess":    "conversion error (ffmpeg)",
+	"reason.disk":           "disk error",
+	"reason.timeout":        "took too long",
+
+	"ledger.openError":      "Error opening %s: %v",
+	"ledger.imported.one":   "📦 Imported %d song from %s (original kept as %s)",
+	"ledger.imported.other": "📦 Imported %d songs from %s (original kept as %s)",
+	"ledger.writeError":     "Error writing to %s: %v",
+
+	"list.empty":       "📝 No songs downloaded yet",
+	"list.title":       "\n📋 Already downloaded songs:",
+	"list.total.one":   "\nTotal: %d song downloaded",
+	"list.total.other": "\nTotal: %d songs downloaded",
+
+	"folder.unnamed":    "UnnamedFolder",
+	"tree.readError":    "❌ Error reading directory: %v",
+	"tree.folder.one":   "📁 %s/ (%d song)",
+	"tree.folder.other": "📁 %s/ (%d songs)",
+	"tree.more":         "   ... and %d more",
+	"tree.total":        "\n🎯 Total songs on disk: %d",
+	"tree.empty":        "   (no artist folders yet)",
+
+	"example.header":  "# Songs file to download\n# Format: artist - song\n# Also a URL, or artist - song @ URL to pick the video, and options such as\n# [flac], [album: name], [no-cookies] or [max-duration: 6m] after the song\n",
+	"example.error":   "Error creating example file: %v",
+	"example.created": "✅ %s file created with examples",
+
+	"session.found.one":         "⏸️  The last session stopped with %d song left. Choose 8 to resume it.",
+	"session.found.other":       "⏸️  The last session stopped with %d songs left. Choose 8 to resume it.",
+	"session.resuming.one":      "⏯️  Resuming the last session: %d song left",
+	"session.resuming.other":    "⏯️  Resuming the last session: %d songs left",
+	"session.interrupted.one":   "⏸️  Stopped with %d song left. Run \"leumusic resume\" or choose 8 in the menu to continue.",
+	"session.interrupted.other": "⏸️  Stopped with %d songs left. Run \"leumusic resume\" or choose 8 in the menu to continue.",
+	"session.none":              "No interrupted session to resume",
+	"session.openError":         "Error opening %s: %v",
+	"session.writeError":        "Error writing to %s: %v",
+
+	"failed.header":            "# Songs that failed to download, with the reason above each one.\n# Retry them with \"leumusic retry\" or option 13, or edit this file and\n# run \"leumusic download --file failed.txt\". Parked songs are commented out.\n",
+	"failed.entry.one":         "# %s: %s (failed %d time)",
+	"failed.entry.other":       "# %s: %s (failed %d times in a row)",
+	"failed.parkedEntry.one":   "# Parked, %s: %s (failed %d time)",
+	"failed.parkedEntry.other": "# Parked, %s: %s (failed %d times in a row)",
+	"failed.title":             "\n❌ Failed songs:",
+	"failed.saved":             "📝 Saved to %s (and %s, %s): retry them with option 13 or \"leumusic retry\"",
+	"failed.parked.one":        "🅿️  %d song has failed too many times in a row and was parked: \"leumusic retry --parked\" tries it again",
+	"failed.parked.other":      "🅿️  %d songs have failed too many times in a row and were parked: \"leumusic retry --parked\" tries them again",
+	"failed.retrying.one":      "🔁 Retrying %d failed song",
+	"failed.retrying.other":    "🔁 Retrying %d failed songs",
+	"failed.none":              "No failed songs to retry",
+	"failed.onlyParked.one":    "Only %d parked song is left: \"leumusic retry --parked\" tries it again",
+	"failed.onlyParked.other":  "Only %d parked songs are left: \"leumusic retry --parked\" tries them again",
+	"failed.openError":         "Error opening %s: %v",
+	"failed.writeError":        "Error writing to %s: %v",
+
+	"playlist.listing":     "📃 Listing %s...",
+	"playlist.found.one":   "📃 %s: %d song",
+	"playlist.found.other": "📃 %s: %d songs",
+	"playlist.saved":       "📃 Playlist saved to %s (%d of %d songs)",
+	"playlist.writeError":  "Error writing to %s: %v",
+
+	"sync.none":            "📃 No playlist is synced yet. Start with: leumusic sync <playlist URL>",
+	"sync.notPlaylist":     "❌ Not a playlist or channel URL: %s",
+	"sync.upToDate":        "📃 %s is up to date",
+	"sync.changes":         "📃 %s: %d to add, %d to remove",
+	"sync.dryRun":          "👀 Dry run: nothing was downloaded, archived or deleted",
+	"sync.archived":        "   📦 %s moved to %s",
+	"sync.restored":        "   📦 %s moved back to %s",
+	"sync.deleted":         "   🗑️ %s deleted",
+	"sync.removeError":     "Error removing %s: %v",
+	"sync.openError":       "Error opening %s: %v",
+	"sync.writeError":      "Error writing to %s: %v",
+	"bench.starting.one":   "⏱️  Benchmarking %d song: one yt-dlp per song, then batches of %d",
+	"bench.starting.other": "⏱️  Benchmarking %d songs: one yt-dlp per song, then batches of %d",
+	"bench.single":         "   One per song:   %s (%s per song), %d/%d downloaded",
+	"bench.batched":        "   Batches of %d:   %s (%s per song), %d/%d downloaded",
+	"bench.speedup":        "   Batching was %.2fx as fast",
+	"bench.noSongs":        "No songs to benchmark",
+
+	"cli.turboQuality": "--turbo and --quality cannot be combined",
+	"cli.workers":      "--workers must be positive",
+	"cli.batch":        "--batch must be positive",
+	"cli.usage": `Usage: leumusic [command] [flags] [arguments]
+
+Without a command the interactive menu is shown.
+
+Commands:
+  download [--file %s]   Download every new song listed in a file
+  add ["artist - song" ...]     Download the given songs (reads stdin if none)
+  list                          Show already downloaded songs
+  check                         Check yt-dlp and ffmpeg
+  tree                          Show the artist folder structure
+  resume                        Continue the last interrupted session
+  retry [--parked]              Download the songs that failed again (--parked: parked ones too)
+  sync [--dry-run] [URL ...]    Download what is new in synced playlists and archive or delete what was removed
+  bench ["artist - song" ...]   Time batched against one-by-one downloads (nothing is kept)
+  help                          Show this help
+
+Flags:
+  --turbo               Turbo Mode (lowest quality, fastest)
+  --quality             Quality Mode (highest quality)
+  --cookies             Use browser cookies to avoid blocks
+  --cookie-source SPEC  Where to read cookies: browser[:profile[::container]] or a cookies.txt file
+  --no-auto-cookies     Don't retry bot-checked or age-restricted downloads with cookies
+  --workers N           Number of concurrent downloads
+  --batch N             Songs fetched per yt-dlp process (0 = one each)
+  --providers LIST      Search providers in fallback order (youtube, youtube-music, soundcloud)
+  --include WORDS       Only pick results whose title has all these words
+  --exclude WORDS       Skip results with these words (default: live, cover, karaoke, 8d, slowed, sped up, remix)
+  --min-duration D      Shortest result to pick (default 60s, 0 = no limit)
+  --max-duration D      Longest result to pick (default 15m, 0 = no limit)
+  --task-timeout D      Longest one song may take (default 30m, 0 = no limit)
+  --run-timeout D       Longest the whole run may take (0 = no limit)
+  --lang CODE           Interface language (%s)
+
+Exit codes:
+  0    everything succeeded (or nothing to do)
+  1    some downloads failed
+  2    every download failed, or a required tool is missing
+  3    invalid usage or missing input file
+  124  run_timeout reached; "resume" continues
+  130  stopped with Ctrl+C or SIGTERM; "resume" continues
+`,
+}
@@ -0,0 +1,256 @@
+package main
+
+var catalogES = catalog{
+	"file.songs":        "canciones.txt",
+	"file.legacyLedger": "descargadas.txt",
+
+	"config.problem": "⚠️  Problema en la configuración: %v",
+
+	"menu.options":     "\nOpciones:",
+	"menu.download":    "1. Descargar desde archivo %s",
+	"menu.manual":      "2. Agregar canciones manualmente",
+	"menu.cookies":     "3. Uso de cookies ( %v )",
+	"menu.settings":    "4. Configuración",
+	"menu.check":       "5. Verificar herramientas",
+	"menu.tree":        "6. Mostrar estructura de carpetas",
+	"menu.list":        "7. Ver canciones descargadas",
+	"menu.resume":      "8. Reanudar la última sesión (faltan %d)",
+	"menu.retry":       "13. Reintentar canciones fallidas (%d)",
+	"menu.sync":        "14. Sincronizar playlists (%d)",
+	"menu.exit":        "9. Salir",
+	"menu.performance": "-------------------- Rendimiento --------------------",
+	"menu.recommended": "🔧 Workers recomendados para tu PC: %d",
+	"menu.turbo":       "10. Modo Turbo ( %v )",
+	"menu.quality":     "11. Modo Calidad ( %v )",
+	"menu.reset":       "12. Restablecer Configuraciones Predeterminadas",
+	"menu.select":      "Selecciona: ",
+	"menu.invalid":     "Opción inválida",
+
+	"settings.title":               "\n⚙️  Configuración",
+	"settings.cookies":             "1. Uso de cookies ( %v )",
+	"settings.turbo":               "2. Modo Turbo ( %v )",
+	"settings.quality":             "3. Modo Calidad ( %v )",
+	"settings.workers":             "4. Workers (%s)",
+	"settings.ytdlp":               "5. Ruta de yt-dlp (%s)",
+	"settings.ffmpeg":              "6. Ruta de ffmpeg (%s)",
+	"settings.language":            "7. Idioma (%s)",
+	"settings.match":               "8. Umbral de coincidencia de duplicados (%s)",
+	"settings.autoCookies":         "9. Reintentar descargas bloqueadas con cookies ( %v )",
+	"settings.cookieSource":        "10. Origen de las cookies (%s)",
+	"settings.back":                "0. Volver",
+	"settings.workersPrompt":       "Workers (0 = auto): ",
+	"settings.invalidNumber":       "❌ Número inválido",
+	"settings.ytdlpPrompt":         "Ruta de yt-dlp (vacío = auto): ",
+	"settings.ffmpegPrompt":        "Ruta de ffmpeg (vacío = auto): ",
+	"settings.languagePrompt":      "Idioma (%s, vacío = sistema): ",
+	"settings.matchPrompt":         "Umbral de coincidencia (0-1, 0 = por defecto %.2f): ",
+	"settings.cookieSourcePrompt":  "Origen de las cookies (%s, navegador:perfil, navegador:perfil::contenedor, ruta de cookies.txt o none; vacío = por defecto): ",
+	"settings.invalidCookieSource": "❌ Origen de cookies inválido: %v",
+	"settings.unknownLanguage":     "❌ Idioma desconocido: %s",
+	"settings.saveFailed":          "❌ No se pudo guardar la configuración: %v",
+	"settings.saved":               "💾 Configuración guardada en %s",
+
+	"cookies.state": "🛡️  Uso de cookies: %v",
+	"cookies.on":    "💡 Se intentará usar cookies del navegador para evitar bloqueos",
+	"cookies.off":   "💡 Se desactivó el uso de cookies (puede haber más bloqueos)",
+
+	"turbo.qualityOff": "⚠️  Modo Calidad DESACTIVADO automáticamente al activar Modo Turbo",
+	"turbo.on":         "🚀 Modo Turbo ACTIVADO (workers: %d, calidad: baja)",
+	"turbo.onHint":     "💡 Se usará la calidad más baja para máxima velocidad",
+	"turbo.off":        "🐢 Modo Turbo DESACTIVADO (calidad: normal)",
+	"quality.turboOff": "⚠️  Modo Turbo DESACTIVADO automáticamente al activar Modo Calidad",
+	"quality.on":       "🎵 Modo Calidad ACTIVADO (workers: %d, calidad: alta)",
+	"quality.onHint":   "💡 Se usará la calidad más alta para el mejor audio",
+	"quality.off":      "🐢 Modo Calidad DESACTIVADO (calidad: normal)",
+	"modes.reset":      "⚙️  Modos Desactivados. Volviendo a la configuración normal.",
+
+	"tools.checking":      "\n🔍 Verificando herramientas necesarias...",
+	"tools.missing":       "❌ %s: NO INSTALADO",
+	"tools.install":       "\n⚠️  Instala las herramientas faltantes:",
+	"tools.placeHint":     "💡 O coloca los binarios junto a leumusic, o define LEUMUSIC_YTDLP / LEUMUSIC_FFMPEG",
+	"tools.cookies.one":   "🍪 Cookies (%s): %d cookie para %s",
+	"tools.cookies.other": "🍪 Cookies (%s): %d cookies para %s",
+	"tools.cookiesEmpty":  "⚠️  Cookies (%s): ninguna para %s, inicia sesión en ese navegador",
+	"tools.cookiesError":  "⚠️  No se pueden leer las cookies (%s): %v",
+
+	"file.notFound":        "❌ No se encontró %s",
+	"file.creatingExample": "📝 Creando archivo de ejemplo...",
+
+	"songs.alreadyDownloaded": "⏭️  Ya descargada: %s",
+	"songs.duplicate":         "⏭️  Ya está en la lista: %s (como %s)",
+	"songs.otherPlaylist":     "🔗 También en la playlist %s: %s (se descarga una vez)",
+	"songs.parked.one":        "⏭️  Aparcada: %s falló %d vez seguida (\"leumusic retry --parked\" la vuelve a intentar)",
+	"songs.parked.other":      "⏭️  Aparcada: %s falló %d veces seguidas (\"leumusic retry --parked\" la vuelve a intentar)",
+	"songs.invalidLine":       "⚠️  Línea %d, columna %d: %s\n   %s\n   %s",
+	"songs.noneNew":           "🎯 No hay canciones nuevas para descargar. Saltadas: %d",
+	"songs.found.one":         "🎶 Encontrada %d canción nueva (%d saltadas)",
+	"songs.found.other":       "🎶 Encontradas %d canciones nuevas (%d saltadas)",
+	"import.read.one":         "📥 Leída %d canción de %s",
+	"import.read.other":       "📥 Leídas %d canciones de %s",
+	"import.readError":        "❌ No se pudo leer la lista de canciones: %v",
+
+	"manual.intro":      "\n📝 Ingresa canciones (formato: artista - canción)",
+	"manual.example":    "Ejemplo: pearl jam - even flow",
+	"manual.finish":     "Escribe '%s' para terminar",
+	"manual.finishWord": "fin",
+	"manual.added":      "✅ Agregado: %s",
+	"manual.invalid":    "❌ Formato inválido en la columna %d: %s. Usa: artista - canción, una URL, o artista - canción @ URL",
+
+	"download.starting.one":     "\n🚀 Iniciando descarga TURBO de %d canción...",
+	"download.starting.other":   "\n🚀 Iniciando descarga TURBO de %d canciones...",
+	"download.workers":          "⚡ Usando %d workers concurrentes",
+	"download.workersAdaptive":  "⚡ Empezando con %d workers simultáneos, ajustados sobre la marcha (hasta %d)",
+	"download.progress":         "\r📊 Progreso: %.1f%% (%d/%d) - ✅%d ❌%d - ⚡%d - 🔎%d(+%d) ⬇️%d(+%d) 🎛️%d(+%d)",
+	"download.completed":        "\n\n🎊 Descargas completadas en %v!",
+	"download.success":          "✅ Éxitos: %d",
+	"download.failed":           "❌ Fallos: %d",
+	"download.pauses.one":       "⏸️  Las descargas se pausaron %d vez por bloqueos",
+	"download.pauses.other":     "⏸️  Las descargas se pausaron %d veces por bloqueos",
+	"download.stopping":         "\n🛑 Deteniendo: cerrando yt-dlp y ffmpeg (Ctrl+C otra vez para salir ya)",
+	"download.started":          "   ▶️  %d. %s",
+	"download.cancelHint":       "💡 Escribe el número de una canción y pulsa Enter para cancelarla",
+	"download.cancelled":        "   ✋ Cancelada: %s",
+	"download.cancelUnknown":    "   ❓ Ninguna canción %s se está descargando o esperando",
+	"download.cancels.one":      "✋ %d canción cancelada",
+	"download.cancels.other":    "✋ %d canciones canceladas",
+	"download.runTimeout":       "\n⏱️  Se alcanzó run_timeout (%s): deteniendo yt-dlp y ffmpeg",
+	"download.registered":       "📝 Registradas en: %s",
+	"download.structure":        "\n📁 Estructura actual de carpetas:",
+	"download.existsOnDisk":     "   ⏭️  Ya existe en disco: %s",
+	"download.error":            "   🔥 Error: %s",
+	"download.failedTask":       "   🔥 %s: %s (%v)",
+	"download.retrying":         "   🔁 %s: %s, reintentando en %v (intento %d)",
+	"download.cookiesEscalated": "   🍪 %s: %s, reintentando con cookies del navegador",
+	"download.cookiesSticky":    "🍪 Muchas descargas están siendo bloqueadas, se usarán cookies del navegador el resto de la sesión",
+	"download.paused":           "   ⏸️  %d solicitudes bloqueadas seguidas (%s), pausando todas las descargas durante %v",
+	"download.resumed":          "   ▶️  Reanudando las descargas tras una pausa de %v",
+
+	"reason.unknown":        "error desconocido",
+	"reason.bot-check":      "verificación anti-bots",
+	"reason.rate-limited":   "límite de solicitudes",
+	"reason.geo-blocked":    "bloqueado en tu país",
+	"reason.age-restricted": "restricción de edad",
+	"reason.unavailable":    "video no disponible",
+	"reason.network":        "error de red",
+	"reason.postprocess":    "error de conversión (ffmpeg)",
+	"reason.disk":           "error de disco",
+	"reason.timeout":        "tardó demasiado",
+
+	"ledger.openError":      "Error abriendo %s: %v",
+	"ledger.imported.one":   "📦 Importada %d canción de %s (original guardado como %s)",
+	"ledger.imported.other": "📦 Importadas %d canciones de %s (original guardado como %s)",
+	"ledger.writeError":     "Error escribiendo en %s: %v",
+
+	"list.empty":       "📝 Aún no hay canciones descargadas",
+	"list.title":       "\n📋 Canciones ya descargadas:",
+	"list.total.one":   "\nTotal: %d canción descargada",
+	"list.total.other": "\nTotal: %d canciones descargadas",
+
+	"folder.unnamed":    "CarpetaSinNombre",
+	"tree.readError":    "❌ Error leyendo directorio: %v",
+	"tree.folder.one":   "📁 %s/ (%d canción)",
+	"tree.folder.other": "📁 %s/ (%d canciones)",
+	"tree.more":         "   ... y %d más",
+	"tree.total":        "\n🎯 Total de canciones en disco: %d",
+	"tree.empty":        "   (no hay carpetas de artistas aún)",
+
+	"example.header":  "# Archivo de canciones para descargar\n# Formato: artista - canción\n# También una URL, o artista - canción @ URL para elegir el video, y opciones como\n# [flac], [album: nombre], [no-cookies] o [max-duration: 6m] tras la canción\n",
+	"example.error":   "Error creando archivo de ejemplo: %v",
+	"example.created": "✅ Archivo %s creado con ejemplos",
+
+	"session.found.one":         "⏸️  La última sesión se detuvo con %d canción pendiente. Elige 8 para reanudarla.",
+	"session.found.other":       "⏸️  La última sesión se detuvo con %d canciones pendientes. Elige 8 para reanudarla.",
+	"session.resuming.one":      "⏯️  Reanudando la última sesión: %d canción pendiente",
+	"session.resuming.other":    "⏯️  Reanudando la última sesión: %d canciones pendientes",
+	"session.interrupted.one":   "⏸️  Detenido con %d canción pendiente. Ejecuta \"leumusic resume\" o elige 8 en el menú para continuar.",
+	"session.interrupted.other": "⏸️  Detenido con %d canciones pendientes. Ejecuta \"leumusic resume\" o elige 8 en el menú para continuar.",
+	"session.none":              "No hay ninguna sesión interrumpida para reanudar",
+	"session.openError":         "Error abriendo %s: %v",
+	"session.writeError":        "Error escribiendo en %s: %v",
+
+	"failed.header":            "# Canciones que no se pudieron descargar, con el motivo encima de cada una.\n# Reinténtalas con \"leumusic retry\" o la opción 13, o edita este archivo y\n# ejecuta \"leumusic download --file failed.txt\". Las aparcadas están comentadas.\n",
+	"failed.entry.one":         "# %s: %s (falló %d vez)",
+	"failed.entry.other":       "# %s: %s (falló %d veces seguidas)",
+	"failed.parkedEntry.one":   "# Aparcada, %s: %s (falló %d vez)",
+	"failed.parkedEntry.other": "# Aparcada, %s: %s (falló %d veces seguidas)",
+	"failed.title":             "\n❌ Canciones fallidas:",
+	"failed.saved":             "📝 Guardadas en %s (y %s, %s): reinténtalas con la opción 13 o \"leumusic retry\"",
+	"failed.parked.one":        "🅿️  %d canción falló demasiadas veces seguidas y quedó aparcada: \"leumusic retry --parked\" la vuelve a intentar",
+	"failed.parked.other":      "🅿️  %d canciones fallaron demasiadas veces seguidas y quedaron aparcadas: \"leumusic retry --parked\" las vuelve a intentar",
+	"failed.retrying.one":      "🔁 Reintentando %d canción fallida",
+	"failed.retrying.other":    "🔁 Reintentando %d canciones fallidas",
+	"failed.none":              "No hay canciones fallidas para reintentar",
+	"failed.onlyParked.one":    "Solo queda %d canción aparcada: \"leumusic retry --parked\" la vuelve a intentar",
+	"failed.onlyParked.other":  "Solo quedan %d canciones aparcadas: \"leumusic retry --parked\" las vuelve a intentar",
+	"failed.openError":         "Error abriendo %s: %v",
+	"failed.writeError":        "Error escribiendo en %s: %v",
+
+	"playlist.listing":     "📃 Listando %s...",
+	"playlist.found.one":   "📃 %s: %d canción",
+	"playlist.found.other": "📃 %s: %d canciones",
+	"playlist.saved":       "📃 Playlist guardada en %s (%d de %d canciones)",
+	"playlist.writeError":  "Error escribiendo en %s: %v",
+
+	"sync.none":            "📃 Aún no hay playlists sincronizadas. Empieza con: leumusic sync <URL de la playlist>",
+	"sync.notPlaylist":     "❌ No es la URL de una playlist o canal: %s",
+	"sync.upToDate":        "📃 %s está al día",
+	"sync.changes":         "📃 %s: %d por añadir, %d por quitar",
+	"sync.dryRun":          "👀 Simulación: no se descargó, archivó ni borró nada",
+	"sync.archived":        "   📦 %s movida a %s",
+	"sync.restored":        "   📦 %s devuelta a %s",
+	"sync.deleted":         "   🗑️ %s borrada",
+	"sync.removeError":     "Error quitando %s: %v",
+	"sync.openError":       "Error abriendo %s: %v",
+	"sync.writeError":      "Error escribiendo en %s: %v",
+	"bench.starting.one":   "⏱️  Midiendo %d canción: un yt-dlp por canción, luego lotes de %d",
+	"bench.starting.other": "⏱️  Midiendo %d canciones: un yt-dlp por canción, luego lotes de %d",
+	"bench.single":         "   Uno por canción: %s (%s por canción), %d/%d descargadas",
+	"bench.batched":        "   Lotes de %d:     %s (%s por canción), %d/%d descargadas",
+	"bench.speedup":        "   Los lotes fueron %.2fx más rápidos",
+	"bench.noSongs":        "No hay canciones para medir",
+
+	"cli.turboQuality": "--turbo y --quality no se pueden combinar",
+	"cli.workers":      "--workers debe ser positivo",
+	"cli.batch":        "--batch debe ser positivo",
+	"cli.usage": `Uso: leumusic [comando] [opciones] [argumentos]
+
+Sin comando se muestra el menú interactivo.
+
+Comandos:
+  download [--file %s]   Descarga las canciones nuevas de un archivo
+  add ["artista - canción" ...]     Descarga las canciones indicadas (lee stdin si no hay)
+  list                              Muestra las canciones ya descargadas
+  check                             Verifica yt-dlp y ffmpeg
+  tree                              Muestra la estructura de carpetas
+  resume                            Continúa la última sesión interrumpida
+  retry [--parked]                  Vuelve a descargar las canciones que fallaron (--parked: también las aparcadas)
+  sync [--dry-run] [URL ...]        Descarga lo nuevo de las playlists sincronizadas y archiva o borra lo quitado
+  bench ["artista - canción" ...]   Compara descargas en lotes con una por una (no se guarda nada)
+  help                              Muestra esta ayuda
+
+Opciones:
+  --turbo                 Modo Turbo (calidad más baja, más rápido)
+  --quality               Modo Calidad (calidad más alta)
+  --cookies               Usar cookies del navegador para evitar bloqueos
+  --cookie-source ORIGEN  De dónde leer las cookies: navegador[:perfil[::contenedor]] o un archivo cookies.txt
+  --no-auto-cookies       No reintentar con cookies las descargas bloqueadas por verificación o edad
+  --workers N             Número de descargas concurrentes
+  --batch N               Canciones por proceso de yt-dlp (0 = una por proceso)
+  --providers LISTA       Proveedores de búsqueda en orden de respaldo (youtube, youtube-music, soundcloud)
+  --include PALABRAS      Solo elegir resultados cuyo título tenga todas estas palabras
+  --exclude PALABRAS      Saltar resultados con estas palabras (por defecto: live, cover, karaoke, 8d, slowed, sped up, remix)
+  --min-duration D        Duración mínima del resultado (por defecto 60s, 0 = sin límite)
+  --max-duration D        Duración máxima del resultado (por defecto 15m, 0 = sin límite)
+  --task-timeout D        Tiempo máximo por canción (por defecto 30m, 0 = sin límite)
+  --run-timeout D         Tiempo máximo de toda la ejecución (0 = sin límite)
+  --lang CÓDIGO           Idioma de la interfaz (%s)
+
+Códigos de salida:
+  0    todo salió bien (o no había nada que hacer)
+  1    algunas descargas fallaron
+  2    todas las descargas fallaron, o falta una herramienta necesaria
+  3    uso inválido o archivo de entrada inexistente
+  124  se alcanzó run_timeout; "resume" continúa
+  130  detenido con Ctrl+C o SIGTERM; "resume" continúa
+`,
+}
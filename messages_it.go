@@ -0,0 +1,253 @@
+package main
+
+var catalogIT = catalog{
+	"config.problem": "⚠️  Problema di configurazione: %v",
+
+	"menu.options":     "\nOpzioni:",
+	"menu.download":    "1. Scarica dal file %s",
+	"menu.manual":      "2. Aggiungi canzoni manualmente",
+	"menu.cookies":     "3. Usa i cookie ( %v )",
+	"menu.settings":    "4. Impostazioni",
+	"menu.check":       "5. Verifica strumenti",
+	"menu.tree":        "6. Mostra struttura cartelle",
+	"menu.list":        "7. Vedi canzoni scaricate",
+	"menu.resume":      "8. Riprendi l'ultima sessione (ne mancano %d)",
+	"menu.retry":       "13. Riprova le canzoni non riuscite (%d)",
+	"menu.sync":        "14. Sincronizza le playlist (%d)",
+	"menu.exit":        "9. Esci",
+	"menu.performance": "-------------------- Prestazioni --------------------",
+	"menu.recommended": "🔧 Worker consigliati per il tuo PC: %d",
+	"menu.turbo":       "10. Modalità Turbo ( %v )",
+	"menu.quality":     "11. Modalità Qualità ( %v )",
+	"menu.reset":       "12. Ripristina impostazioni predefinite",
+	"menu.select":      "Seleziona: ",
+	"menu.invalid":     "Opzione non valida",
+
+	"settings.title":               "\n⚙️  Impostazioni",
+	"settings.cookies":             "1. Usa i cookie ( %v )",
+	"settings.turbo":               "2. Modalità Turbo ( %v )",
+	"settings.quality":             "3. Modalità Qualità ( %v )",
+	"settings.workers":             "4. Worker (%s)",
+	"settings.ytdlp":               "5. Percorso di yt-dlp (%s)",
+	"settings.ffmpeg":              "6. Percorso di ffmpeg (%s)",
+	"settings.language":            "7. Lingua (%s)",
+	"settings.match":               "8. Soglia di corrispondenza dei duplicati (%s)",
+	"settings.autoCookies":         "9. Riprova i download bloccati con i cookie ( %v )",
+	"settings.cookieSource":        "10. Origine dei cookie (%s)",
+	"settings.back":                "0. Indietro",
+	"settings.workersPrompt":       "Worker (0 = auto): ",
+	"settings.invalidNumber":       "❌ Numero non valido",
+	"settings.ytdlpPrompt":         "Percorso di yt-dlp (vuoto = auto): ",
+	"settings.ffmpegPrompt":        "Percorso di ffmpeg (vuoto = auto): ",
+	"settings.languagePrompt":      "Lingua (%s, vuoto = sistema): ",
+	"settings.matchPrompt":         "Soglia di corrispondenza (0-1, 0 = predefinita %.2f): ",
+	"settings.cookieSourcePrompt":  "Origine dei cookie (%s, browser:profilo, browser:profilo::contenitore, percorso di cookies.txt o none; vuoto = predefinita): ",
+	"settings.invalidCookieSource": "❌ Origine dei cookie non valida: %v",
+	"settings.unknownLanguage":     "❌ Lingua sconosciuta: %s",
+	"settings.saveFailed":          "❌ Impossibile salvare le impostazioni: %v",
+	"settings.saved":               "💾 Impostazioni salvate in %s",
+
+	"cookies.state": "🛡️  Uso dei cookie: %v",
+	"cookies.on":    "💡 Verranno usati i cookie del browser per evitare blocchi",
+	"cookies.off":   "💡 Cookie disattivati (potrebbero esserci più blocchi)",
+
+	"turbo.qualityOff": "⚠️  Modalità Qualità DISATTIVATA automaticamente attivando la Modalità Turbo",
+	"turbo.on":         "🚀 Modalità Turbo ATTIVATA (worker: %d, qualità: bassa)",
+	"turbo.onHint":     "💡 Verrà usata la qualità più bassa per la massima velocità",
+	"turbo.off":        "🐢 Modalità Turbo DISATTIVATA (qualità: normale)",
+	"quality.turboOff": "⚠️  Modalità Turbo DISATTIVATA automaticamente attivando la Modalità Qualità",
+	"quality.on":       "🎵 Modalità Qualità ATTIVATA (worker: %d, qualità: alta)",
+	"quality.onHint":   "💡 Verrà usata la qualità più alta per il miglior audio",
+	"quality.off":      "🐢 Modalità Qualità DISATTIVATA (qualità: normale)",
+	"modes.reset":      "⚙️  Modalità disattivate. Ritorno alla configurazione normale.",
+
+	"tools.checking":      "\n🔍 Verifica degli strumenti necessari...",
+	"tools.missing":       "❌ %s: NON INSTALLATO",
+	"tools.install":       "\n⚠️  Installa gli strumenti mancanti:",
+	"tools.placeHint":     "💡 Oppure metti i binari accanto a leumusic, o imposta LEUMUSIC_YTDLP / LEUMUSIC_FFMPEG",
+	"tools.cookies.one":   "🍪 Cookie (%s): %d cookie per %s",
+	"tools.cookies.other": "🍪 Cookie (%s): %d cookie per %s",
+	"tools.cookiesEmpty":  "⚠️  Cookie (%s): nessuno per %s, accedi al sito in quel browser",
+	"tools.cookiesError":  "⚠️  Impossibile leggere i cookie (%s): %v",
+
+	"file.notFound":        "❌ File %s non trovato",
+	"file.creatingExample": "📝 Creazione del file di esempio...",
+
+	"songs.alreadyDownloaded": "⏭️  Già scaricata: %s",
+	"songs.duplicate":         "⏭️  Già nella lista: %s (come %s)",
+	"songs.otherPlaylist":     "🔗 Anche nella playlist %s: %s (scaricata una volta)",
+	"songs.parked.one":        "⏭️  Parcheggiata: %s è fallita %d volta di fila (\"leumusic retry --parked\" la riprova)",
+	"songs.parked.other":      "⏭️  Parcheggiata: %s è fallita %d volte di fila (\"leumusic retry --parked\" la riprova)",
+	"songs.invalidLine":       "⚠️  Riga %d, colonna %d: %s\n   %s\n   %s",
+	"songs.noneNew":           "🎯 Nessuna nuova canzone da scaricare. Saltate: %d",
+	"songs.found.one":         "🎶 Trovata %d nuova canzone (%d saltate)",
+	"songs.found.other":       "🎶 Trovate %d nuove canzoni (%d saltate)",
+	"import.read.one":         "📥 Letta %d canzone da %s",
+	"import.read.other":       "📥 Lette %d canzoni da %s",
+	"import.readError":        "❌ Impossibile leggere l'elenco delle canzoni: %v",
+
+	"manual.intro":      "\n📝 Inserisci le canzoni (formato: artista - canzone)",
+	"manual.example":    "Esempio: pearl jam - even flow",
+	"manual.finish":     "Scrivi '%s' per terminare",
+	"manual.finishWord": "fine",
+	"manual.added":      "✅ Aggiunta: %s",
+	"manual.invalid":    "❌ Formato non valido alla colonna %d: %s. Usa: artista - canzone, un URL, o artista - canzone @ URL",
+
+	"download.starting.one":     "\n🚀 Avvio del download TURBO di %d canzone...",
+	"download.starting.other":   "\n🚀 Avvio del download TURBO di %d canzoni...",
+	"download.workers":          "⚡ Uso di %d worker simultanei",
+	"download.workersAdaptive":  "⚡ Si parte con %d worker simultanei, regolati durante il download (fino a %d)",
+	"download.progress":         "\r📊 Avanzamento: %.1f%% (%d/%d) - ✅%d ❌%d - ⚡%d - 🔎%d(+%d) ⬇️%d(+%d) 🎛️%d(+%d)",
+	"download.completed":        "\n\n🎊 Download completati in %v!",
+	"download.success":          "✅ Riusciti: %d",
+	"download.failed":           "❌ Falliti: %d",
+	"download.pauses.one":       "⏸️  I download sono stati messi in pausa %d volta per blocchi",
+	"download.pauses.other":     "⏸️  I download sono stati messi in pausa %d volte per blocchi",
+	"download.stopping":         "\n🛑 Interruzione: chiusura di yt-dlp e ffmpeg (Ctrl+C di nuovo per uscire subito)",
+	"download.started":          "   ▶️  %d. %s",
+	"download.cancelHint":       "💡 Scrivi il numero di una canzone e premi Invio per annullarla",
+	"download.cancelled":        "   ✋ Annullata: %s",
+	"download.cancelUnknown":    "   ❓ Nessuna canzone %s in download o in attesa",
+	"download.cancels.one":      "✋ %d canzone annullata",
+	"download.cancels.other":    "✋ %d canzoni annullate",
+	"download.runTimeout":       "\n⏱️  run_timeout (%s) raggiunto: arresto di yt-dlp e ffmpeg",
+	"download.registered":       "📝 Registrate in: %s",
+	"download.structure":        "\n📁 Struttura attuale delle cartelle:",
+	"download.existsOnDisk":     "   ⏭️  Già presente su disco: %s",
+	"download.error":            "   🔥 Errore: %s",
+	"download.failedTask":       "   🔥 %s: %s (%v)",
+	"download.retrying":         "   🔁 %s: %s, nuovo tentativo tra %v (tentativo %d)",
+	"download.cookiesEscalated": "   🍪 %s: %s, nuovo tentativo con i cookie del browser",
+	"download.cookiesSticky":    "🍪 Molti download vengono bloccati, verranno usati i cookie del browser per il resto della sessione",
+	"download.paused":           "   ⏸️  %d richieste bloccate di fila (%s), pausa di tutti i download per %v",
+	"download.resumed":          "   ▶️  Ripresa dei download dopo una pausa di %v",
+
+	"reason.unknown":        "errore sconosciuto",
+	"reason.bot-check":      "verifica anti-bot",
+	"reason.rate-limited":   "limite di richieste",
+	"reason.geo-blocked":    "bloccato nel tuo paese",
+	"reason.age-restricted": "limite di età",
+	"reason.unavailable":    "video non disponibile",
+	"reason.network":        "errore di rete",
+	"reason.postprocess":    "errore di conversione (ffmpeg)",
+	"reason.disk":           "errore del disco",
+	"reason.timeout":        "ha impiegato troppo",
+
+	"ledger.openError":      "Errore nell'apertura di %s: %v",
+	"ledger.imported.one":   "📦 Importata %d canzone da %s (originale conservato come %s)",
+	"ledger.imported.other": "📦 Importate %d canzoni da %s (originale conservato come %s)",
+	"ledger.writeError":     "Errore nella scrittura di %s: %v",
+
+	"list.empty":       "📝 Nessuna canzone scaricata finora",
+	"list.title":       "\n📋 Canzoni già scaricate:",
+	"list.total.one":   "\nTotale: %d canzone scaricata",
+	"list.total.other": "\nTotale: %d canzoni scaricate",
+
+	"folder.unnamed":    "CartellaSenzaNome",
+	"tree.readError":    "❌ Errore nella lettura della cartella: %v",
+	"tree.folder.one":   "📁 %s/ (%d canzone)",
+	"tree.folder.other": "📁 %s/ (%d canzoni)",
+	"tree.more":         "   ... e altre %d",
+	"tree.total":        "\n🎯 Totale canzoni su disco: %d",
+	"tree.empty":        "   (ancora nessuna cartella di artisti)",
+
+	"example.header":  "# File di canzoni da scaricare\n# Formato: artista - canzone\n# Anche un URL, o artista - canzone @ URL per scegliere il video, e opzioni come\n# [flac], [album: nome], [no-cookies] o [max-duration: 6m] dopo la canzone\n",
+	"example.error":   "Errore nella creazione del file di esempio: %v",
+	"example.created": "✅ File %s creato con esempi",
+
+	"session.found.one":         "⏸️  L'ultima sessione si è fermata con %d canzone in sospeso. Scegli 8 per riprenderla.",
+	"session.found.other":       "⏸️  L'ultima sessione si è fermata con %d canzoni in sospeso. Scegli 8 per riprenderla.",
+	"session.resuming.one":      "⏯️  Riprendo l'ultima sessione: %d canzone in sospeso",
+	"session.resuming.other":    "⏯️  Riprendo l'ultima sessione: %d canzoni in sospeso",
+	"session.interrupted.one":   "⏸️  Fermato con %d canzone in sospeso. Esegui \"leumusic resume\" o scegli 8 nel menu per continuare.",
+	"session.interrupted.other": "⏸️  Fermato con %d canzoni in sospeso. Esegui \"leumusic resume\" o scegli 8 nel menu per continuare.",
+	"session.none":              "Nessuna sessione interrotta da riprendere",
+	"session.openError":         "Errore nell'apertura di %s: %v",
+	"session.writeError":        "Errore nella scrittura di %s: %v",
+
+	"failed.header":            "# Canzoni che non è stato possibile scaricare, con il motivo sopra ciascuna.\n# Riprovale con \"leumusic retry\" o l'opzione 13, oppure modifica questo file ed\n# esegui \"leumusic download --file failed.txt\". Quelle parcheggiate sono commentate.\n",
+	"failed.entry.one":         "# %s: %s (fallita %d volta)",
+	"failed.entry.other":       "# %s: %s (fallita %d volte di fila)",
+	"failed.parkedEntry.one":   "# Parcheggiata, %s: %s (fallita %d volta)",
+	"failed.parkedEntry.other": "# Parcheggiata, %s: %s (fallita %d volte di fila)",
+	"failed.title":             "\n❌ Canzoni non riuscite:",
+	"failed.saved":             "📝 Salvate in %s (e %s, %s): riprovale con l'opzione 13 o \"leumusic retry\"",
+	"failed.parked.one":        "🅿️  %d canzone è fallita troppe volte di fila ed è stata parcheggiata: \"leumusic retry --parked\" la riprova",
+	"failed.parked.other":      "🅿️  %d canzoni sono fallite troppe volte di fila e sono state parcheggiate: \"leumusic retry --parked\" le riprova",
+	"failed.retrying.one":      "🔁 Riprovo %d canzone non riuscita",
+	"failed.retrying.other":    "🔁 Riprovo %d canzoni non riuscite",
+	"failed.none":              "Nessuna canzone non riuscita da riprovare",
+	"failed.onlyParked.one":    "Resta solo %d canzone parcheggiata: \"leumusic retry --parked\" la riprova",
+	"failed.onlyParked.other":  "Restano solo %d canzoni parcheggiate: \"leumusic retry --parked\" le riprova",
+	"failed.openError":         "Errore nell'apertura di %s: %v",
+	"failed.writeError":        "Errore nella scrittura di %s: %v",
+
+	"playlist.listing":     "📃 Elenco %s...",
+	"playlist.found.one":   "📃 %s: %d canzone",
+	"playlist.found.other": "📃 %s: %d canzoni",
+	"playlist.saved":       "📃 Playlist salvata in %s (%d di %d canzoni)",
+	"playlist.writeError":  "Errore nella scrittura di %s: %v",
+
+	"sync.none":            "📃 Nessuna playlist sincronizzata. Inizia con: leumusic sync <URL della playlist>",
+	"sync.notPlaylist":     "❌ Non è l'URL di una playlist o di un canale: %s",
+	"sync.upToDate":        "📃 %s è aggiornata",
+	"sync.changes":         "📃 %s: %d da aggiungere, %d da rimuovere",
+	"sync.dryRun":          "👀 Prova: niente è stato scaricato, archiviato o eliminato",
+	"sync.archived":        "   📦 %s spostata in %s",
+	"sync.restored":        "   📦 %s riportata in %s",
+	"sync.deleted":         "   🗑️ %s eliminata",
+	"sync.removeError":     "Errore nella rimozione di %s: %v",
+	"sync.openError":       "Errore nell'apertura di %s: %v",
+	"sync.writeError":      "Errore nella scrittura di %s: %v",
+	"bench.starting.one":   "⏱️  Misuro %d canzone: un yt-dlp per canzone, poi lotti da %d",
+	"bench.starting.other": "⏱️  Misuro %d canzoni: un yt-dlp per canzone, poi lotti da %d",
+	"bench.single":         "   Uno per canzone: %s (%s per canzone), %d/%d scaricate",
+	"bench.batched":        "   Lotti da %d:     %s (%s per canzone), %d/%d scaricate",
+	"bench.speedup":        "   I lotti sono stati %.2fx più veloci",
+	"bench.noSongs":        "Nessuna canzone da misurare",
+
+	"cli.turboQuality": "--turbo e --quality non possono essere combinati",
+	"cli.workers":      "--workers deve essere positivo",
+	"cli.batch":        "--batch deve essere positivo",
+	"cli.usage": `Uso: leumusic [comando] [opzioni] [argomenti]
+
+Senza comando viene mostrato il menu interattivo.
+
+Comandi:
+  download [--file %s]   Scarica le nuove canzoni elencate in un file
+  add ["artista - canzone" ...] Scarica le canzoni indicate (legge stdin se vuoto)
+  list                          Mostra le canzoni già scaricate
+  check                         Verifica yt-dlp e ffmpeg
+  tree                          Mostra la struttura delle cartelle
+  resume                        Continua l'ultima sessione interrotta
+  retry [--parked]              Scarica di nuovo le canzoni non riuscite (--parked: anche quelle parcheggiate)
+  sync [--dry-run] [URL ...]    Scarica le novità delle playlist sincronizzate e archivia o elimina quelle rimosse
+  bench ["artista - canzone" ...] Confronta i download a lotti con quelli uno per uno (non tiene nulla)
+  help                          Mostra questo aiuto
+
+Opzioni:
+  --turbo                  Modalità Turbo (qualità più bassa, più veloce)
+  --quality                Modalità Qualità (qualità più alta)
+  --cookies                Usa i cookie del browser per evitare blocchi
+  --cookie-source ORIGINE  Da dove leggere i cookie: browser[:profilo[::contenitore]] o un file cookies.txt
+  --no-auto-cookies        Non riprovare con i cookie i download bloccati da verifica o età
+  --workers N              Numero di download simultanei
+  --batch N                Canzoni per processo yt-dlp (0 = una per processo)
+  --providers ELENCO       Provider di ricerca in ordine di ripiego (youtube, youtube-music, soundcloud)
+  --include PAROLE         Scegli solo risultati il cui titolo contiene tutte queste parole
+  --exclude PAROLE         Salta i risultati con queste parole (predefinite: live, cover, karaoke, 8d, slowed, sped up, remix)
+  --min-duration D         Durata minima del risultato (predefinita 60s, 0 = nessun limite)
+  --max-duration D         Durata massima del risultato (predefinita 15m, 0 = nessun limite)
+  --task-timeout D         Tempo massimo per canzone (predefinito 30m, 0 = nessun limite)
+  --run-timeout D          Tempo massimo dell'intera esecuzione (0 = nessun limite)
+  --lang CODICE            Lingua dell'interfaccia (%s)
+
+Codici di uscita:
+  0    tutto riuscito (o niente da fare)
+  1    alcuni download sono falliti
+  2    tutti i download sono falliti, o manca uno strumento necessario
+  3    uso non valido o file di input inesistente
+  124  run_timeout raggiunto; "resume" continua
+  130  fermato con Ctrl+C o SIGTERM; "resume" continua
+`,
+}
@@ -0,0 +1,253 @@
+package main
+
+var catalogPT = catalog{
+	"config.problem": "⚠️  Problema na configuração: %v",
+
+	"menu.options":     "\nOpções:",
+	"menu.download":    "1. Baixar do arquivo %s",
+	"menu.manual":      "2. Adicionar músicas manualmente",
+	"menu.cookies":     "3. Usar cookies ( %v )",
+	"menu.settings":    "4. Configurações",
+	"menu.check":       "5. Verificar ferramentas",
+	"menu.tree":        "6. Mostrar estrutura de pastas",
+	"menu.list":        "7. Ver músicas baixadas",
+	"menu.resume":      "8. Retomar a última sessão (faltam %d)",
+	"menu.retry":       "13. Tentar de novo as músicas que falharam (%d)",
+	"menu.sync":        "14. Sincronizar playlists (%d)",
+	"menu.exit":        "9. Sair",
+	"menu.performance": "-------------------- Desempenho --------------------",
+	"menu.recommended": "🔧 Workers recomendados para o seu PC: %d",
+	"menu.turbo":       "10. Modo Turbo ( %v )",
+	"menu.quality":     "11. Modo Qualidade ( %v )",
+	"menu.reset":       "12. Restaurar Configurações Padrão",
+	"menu.select":      "Selecione: ",
+	"menu.invalid":     "Opção inválida",
+
+	"settings.title":               "\n⚙️  Configurações",
+	"settings.cookies":             "1. Usar cookies ( %v )",
+	"settings.turbo":               "2. Modo Turbo ( %v )",
+	"settings.quality":             "3. Modo Qualidade ( %v )",
+	"settings.workers":             "4. Workers (%s)",
+	"settings.ytdlp":               "5. Caminho do yt-dlp (%s)",
+	"settings.ffmpeg":              "6. Caminho do ffmpeg (%s)",
+	"settings.language":            "7. Idioma (%s)",
+	"settings.match":               "8. Limite de correspondência de duplicadas (%s)",
+	"settings.autoCookies":         "9. Tentar downloads bloqueados com cookies ( %v )",
+	"settings.cookieSource":        "10. Origem dos cookies (%s)",
+	"settings.back":                "0. Voltar",
+	"settings.workersPrompt":       "Workers (0 = auto): ",
+	"settings.invalidNumber":       "❌ Número inválido",
+	"settings.ytdlpPrompt":         "Caminho do yt-dlp (vazio = auto): ",
+	"settings.ffmpegPrompt":        "Caminho do ffmpeg (vazio = auto): ",
+	"settings.languagePrompt":      "Idioma (%s, vazio = sistema): ",
+	"settings.matchPrompt":         "Limite de correspondência (0-1, 0 = padrão %.2f): ",
+	"settings.cookieSourcePrompt":  "Origem dos cookies (%s, navegador:perfil, navegador:perfil::contêiner, caminho do cookies.txt ou none; vazio = padrão): ",
+	"settings.invalidCookieSource": "❌ Origem de cookies inválida: %v",
+	"settings.unknownLanguage":     "❌ Idioma desconhecido: %s",
+	"settings.saveFailed":          "❌ Não foi possível salvar as configurações: %v",
+	"settings.saved":               "💾 Configurações salvas em %s",
+
+	"cookies.state": "🛡️  Usar cookies: %v",
+	"cookies.on":    "💡 Os cookies do navegador serão usados para evitar bloqueios",
+	"cookies.off":   "💡 Cookies desativados (pode haver mais bloqueios)",
+
+	"turbo.qualityOff": "⚠️  Modo Qualidade DESATIVADO automaticamente ao ativar o Modo Turbo",
+	"turbo.on":         "🚀 Modo Turbo ATIVADO (workers: %d, qualidade: baixa)",
+	"turbo.onHint":     "💡 Será usada a qualidade mais baixa para máxima velocidade",
+	"turbo.off":        "🐢 Modo Turbo DESATIVADO (qualidade: normal)",
+	"quality.turboOff": "⚠️  Modo Turbo DESATIVADO automaticamente ao ativar o Modo Qualidade",
+	"quality.on":       "🎵 Modo Qualidade ATIVADO (workers: %d, qualidade: alta)",
+	"quality.onHint":   "💡 Será usada a qualidade mais alta para o melhor áudio",
+	"quality.off":      "🐢 Modo Qualidade DESATIVADO (qualidade: normal)",
+	"modes.reset":      "⚙️  Modos desativados. Voltando à configuração normal.",
+
+	"tools.checking":      "\n🔍 Verificando ferramentas necessárias...",
+	"tools.missing":       "❌ %s: NÃO INSTALADO",
+	"tools.install":       "\n⚠️  Instale as ferramentas que faltam:",
+	"tools.placeHint":     "💡 Ou coloque os binários ao lado do leumusic, ou defina LEUMUSIC_YTDLP / LEUMUSIC_FFMPEG",
+	"tools.cookies.one":   "🍪 Cookies (%s): %d cookie para %s",
+	"tools.cookies.other": "🍪 Cookies (%s): %d cookies para %s",
+	"tools.cookiesEmpty":  "⚠️  Cookies (%s): nenhum para %s, faça login nesse navegador",
+	"tools.cookiesError":  "⚠️  Não foi possível ler os cookies (%s): %v",
+
+	"file.notFound":        "❌ Arquivo %s não encontrado",
+	"file.creatingExample": "📝 Criando arquivo de exemplo...",
+
+	"songs.alreadyDownloaded": "⏭️  Já baixada: %s",
+	"songs.duplicate":         "⏭️  Já está na lista: %s (como %s)",
+	"songs.otherPlaylist":     "🔗 Também na playlist %s: %s (baixada uma vez)",
+	"songs.parked.one":        "⏭️  Estacionada: %s falhou %d vez seguida (\"leumusic retry --parked\" tenta de novo)",
+	"songs.parked.other":      "⏭️  Estacionada: %s falhou %d vezes seguidas (\"leumusic retry --parked\" tenta de novo)",
+	"songs.invalidLine":       "⚠️  Linha %d, coluna %d: %s\n   %s\n   %s",
+	"songs.noneNew":           "🎯 Nenhuma música nova para baixar. Ignoradas: %d",
+	"songs.found.one":         "🎶 Encontrada %d música nova (%d ignoradas)",
+	"songs.found.other":       "🎶 Encontradas %d músicas novas (%d ignoradas)",
+	"import.read.one":         "📥 Lida %d música de %s",
+	"import.read.other":       "📥 Lidas %d músicas de %s",
+	"import.readError":        "❌ Não foi possível ler a lista de músicas: %v",
+
+	"manual.intro":      "\n📝 Digite as músicas (formato: artista - música)",
+	"manual.example":    "Exemplo: pearl jam - even flow",
+	"manual.finish":     "Digite '%s' para terminar",
+	"manual.finishWord": "fim",
+	"manual.added":      "✅ Adicionada: %s",
+	"manual.invalid":    "❌ Formato inválido na coluna %d: %s. Use: artista - música, uma URL, ou artista - música @ URL",
+
+	"download.starting.one":     "\n🚀 Iniciando download TURBO de %d música...",
+	"download.starting.other":   "\n🚀 Iniciando download TURBO de %d músicas...",
+	"download.workers":          "⚡ Usando %d workers simultâneos",
+	"download.workersAdaptive":  "⚡ Começando com %d workers simultâneos, ajustados durante o download (até %d)",
+	"download.progress":         "\r📊 Progresso: %.1f%% (%d/%d) - ✅%d ❌%d - ⚡%d - 🔎%d(+%d) ⬇️%d(+%d) 🎛️%d(+%d)",
+	"download.completed":        "\n\n🎊 Downloads concluídos em %v!",
+	"download.success":          "✅ Sucesso: %d",
+	"download.failed":           "❌ Falhas: %d",
+	"download.pauses.one":       "⏸️  Os downloads foram pausados %d vez por bloqueios",
+	"download.pauses.other":     "⏸️  Os downloads foram pausados %d vezes por bloqueios",
+	"download.stopping":         "\n🛑 Parando: fechando yt-dlp e ffmpeg (Ctrl+C de novo para sair já)",
+	"download.started":          "   ▶️  %d. %s",
+	"download.cancelHint":       "💡 Digite o número de uma música e pressione Enter para cancelá-la",
+	"download.cancelled":        "   ✋ Cancelada: %s",
+	"download.cancelUnknown":    "   ❓ Nenhuma música %s está sendo baixada ou aguardando",
+	"download.cancels.one":      "✋ %d música cancelada",
+	"download.cancels.other":    "✋ %d músicas canceladas",
+	"download.runTimeout":       "\n⏱️  run_timeout (%s) atingido: parando yt-dlp e ffmpeg",
+	"download.registered":       "📝 Registradas em: %s",
+	"download.structure":        "\n📁 Estrutura atual de pastas:",
+	"download.existsOnDisk":     "   ⏭️  Já existe no disco: %s",
+	"download.error":            "   🔥 Erro: %s",
+	"download.failedTask":       "   🔥 %s: %s (%v)",
+	"download.retrying":         "   🔁 %s: %s, tentando novamente em %v (tentativa %d)",
+	"download.cookiesEscalated": "   🍪 %s: %s, tentando novamente com cookies do navegador",
+	"download.cookiesSticky":    "🍪 Muitos downloads estão sendo bloqueados, os cookies do navegador serão usados pelo resto da sessão",
+	"download.paused":           "   ⏸️  %d requisições bloqueadas seguidas (%s), pausando todos os downloads por %v",
+	"download.resumed":          "   ▶️  Retomando os downloads após uma pausa de %v",
+
+	"reason.unknown":        "erro desconhecido",
+	"reason.bot-check":      "verificação anti-robô",
+	"reason.rate-limited":   "limite de requisições",
+	"reason.geo-blocked":    "bloqueado no seu país",
+	"reason.age-restricted": "restrição de idade",
+	"reason.unavailable":    "vídeo indisponível",
+	"reason.network":        "erro de rede",
+	"reason.postprocess":    "erro de conversão (ffmpeg)",
+	"reason.disk":           "erro de disco",
+	"reason.timeout":        "demorou demais",
+
+	"ledger.openError":      "Erro ao abrir %s: %v",
+	"ledger.imported.one":   "📦 Importada %d música de %s (original mantido como %s)",
+	"ledger.imported.other": "📦 Importadas %d músicas de %s (original mantido como %s)",
+	"ledger.writeError":     "Erro ao escrever em %s: %v",
+
+	"list.empty":       "📝 Nenhuma música baixada ainda",
+	"list.title":       "\n📋 Músicas já baixadas:",
+	"list.total.one":   "\nTotal: %d música baixada",
+	"list.total.other": "\nTotal: %d músicas baixadas",
+
+	"folder.unnamed":    "PastaSemNome",
+	"tree.readError":    "❌ Erro ao ler o diretório: %v",
+	"tree.folder.one":   "📁 %s/ (%d música)",
+	"tree.folder.other": "📁 %s/ (%d músicas)",
+	"tree.more":         "   ... e mais %d",
+	"tree.total":        "\n🎯 Total de músicas no disco: %d",
+	"tree.empty":        "   (ainda não há pastas de artistas)",
+
+	"example.header":  "# Arquivo de músicas para baixar\n# Formato: artista - música\n# Também uma URL, ou artista - música @ URL para escolher o vídeo, e opções como\n# [flac], [album: nome], [no-cookies] ou [max-duration: 6m] depois da música\n",
+	"example.error":   "Erro ao criar o arquivo de exemplo: %v",
+	"example.created": "✅ Arquivo %s criado com exemplos",
+
+	"session.found.one":         "⏸️  A última sessão parou com %d música pendente. Escolha 8 para retomá-la.",
+	"session.found.other":       "⏸️  A última sessão parou com %d músicas pendentes. Escolha 8 para retomá-la.",
+	"session.resuming.one":      "⏯️  Retomando a última sessão: %d música pendente",
+	"session.resuming.other":    "⏯️  Retomando a última sessão: %d músicas pendentes",
+	"session.interrupted.one":   "⏸️  Parado com %d música pendente. Execute \"leumusic resume\" ou escolha 8 no menu para continuar.",
+	"session.interrupted.other": "⏸️  Parado com %d músicas pendentes. Execute \"leumusic resume\" ou escolha 8 no menu para continuar.",
+	"session.none":              "Nenhuma sessão interrompida para retomar",
+	"session.openError":         "Erro ao abrir %s: %v",
+	"session.writeError":        "Erro ao escrever em %s: %v",
+
+	"failed.header":            "# Músicas que não puderam ser baixadas, com o motivo acima de cada uma.\n# Tente de novo com \"leumusic retry\" ou a opção 13, ou edite este arquivo e\n# execute \"leumusic download --file failed.txt\". As estacionadas estão comentadas.\n",
+	"failed.entry.one":         "# %s: %s (falhou %d vez)",
+	"failed.entry.other":       "# %s: %s (falhou %d vezes seguidas)",
+	"failed.parkedEntry.one":   "# Estacionada, %s: %s (falhou %d vez)",
+	"failed.parkedEntry.other": "# Estacionada, %s: %s (falhou %d vezes seguidas)",
+	"failed.title":             "\n❌ Músicas que falharam:",
+	"failed.saved":             "📝 Salvas em %s (e %s, %s): tente de novo com a opção 13 ou \"leumusic retry\"",
+	"failed.parked.one":        "🅿️  %d música falhou vezes demais seguidas e foi estacionada: \"leumusic retry --parked\" tenta de novo",
+	"failed.parked.other":      "🅿️  %d músicas falharam vezes demais seguidas e foram estacionadas: \"leumusic retry --parked\" tenta de novo",
+	"failed.retrying.one":      "🔁 Tentando de novo %d música que falhou",
+	"failed.retrying.other":    "🔁 Tentando de novo %d músicas que falharam",
+	"failed.none":              "Nenhuma música que falhou para tentar de novo",
+	"failed.onlyParked.one":    "Só resta %d música estacionada: \"leumusic retry --parked\" tenta de novo",
+	"failed.onlyParked.other":  "Só restam %d músicas estacionadas: \"leumusic retry --parked\" tenta de novo",
+	"failed.openError":         "Erro ao abrir %s: %v",
+	"failed.writeError":        "Erro ao escrever em %s: %v",
+
+	"playlist.listing":     "📃 Listando %s...",
+	"playlist.found.one":   "📃 %s: %d música",
+	"playlist.found.other": "📃 %s: %d músicas",
+	"playlist.saved":       "📃 Playlist salva em %s (%d de %d músicas)",
+	"playlist.writeError":  "Erro ao escrever em %s: %v",
+
+	"sync.none":            "📃 Nenhuma playlist sincronizada ainda. Comece com: leumusic sync <URL da playlist>",
+	"sync.notPlaylist":     "❌ Não é a URL de uma playlist ou canal: %s",
+	"sync.upToDate":        "📃 %s está em dia",
+	"sync.changes":         "📃 %s: %d para adicionar, %d para remover",
+	"sync.dryRun":          "👀 Simulação: nada foi baixado, arquivado ou apagado",
+	"sync.archived":        "   📦 %s movida para %s",
+	"sync.restored":        "   📦 %s devolvida para %s",
+	"sync.deleted":         "   🗑️ %s apagada",
+	"sync.removeError":     "Erro ao remover %s: %v",
+	"sync.openError":       "Erro ao abrir %s: %v",
+	"sync.writeError":      "Erro ao escrever em %s: %v",
+	"bench.starting.one":   "⏱️  Medindo %d música: um yt-dlp por música, depois lotes de %d",
+	"bench.starting.other": "⏱️  Medindo %d músicas: um yt-dlp por música, depois lotes de %d",
+	"bench.single":         "   Um por música: %s (%s por música), %d/%d baixadas",
+	"bench.batched":        "   Lotes de %d:   %s (%s por música), %d/%d baixadas",
+	"bench.speedup":        "   Os lotes foram %.2fx mais rápidos",
+	"bench.noSongs":        "Nenhuma música para medir",
+
+	"cli.turboQuality": "--turbo e --quality não podem ser combinados",
+	"cli.workers":      "--workers deve ser positivo",
+	"cli.batch":        "--batch deve ser positivo",
+	"cli.usage": `Uso: leumusic [comando] [opções] [argumentos]
+
+Sem comando é mostrado o menu interativo.
+
+Comandos:
+  download [--file %s]   Baixa as músicas novas listadas em um arquivo
+  add ["artista - música" ...]  Baixa as músicas indicadas (lê stdin se vazio)
+  list                          Mostra as músicas já baixadas
+  check                         Verifica yt-dlp e ffmpeg
+  tree                          Mostra a estrutura de pastas
+  resume                        Continua a última sessão interrompida
+  retry [--parked]              Baixa de novo as músicas que falharam (--parked: também as estacionadas)
+  sync [--dry-run] [URL ...]    Baixa o que há de novo nas playlists sincronizadas e arquiva ou apaga o que foi removido
+  bench ["artista - música" ...]  Compara downloads em lotes com um por um (nada é mantido)
+  help                          Mostra esta ajuda
+
+Opções:
+  --turbo                 Modo Turbo (qualidade mais baixa, mais rápido)
+  --quality               Modo Qualidade (qualidade mais alta)
+  --cookies               Usar cookies do navegador para evitar bloqueios
+  --cookie-source ORIGEM  De onde ler os cookies: navegador[:perfil[::contêiner]] ou um arquivo cookies.txt
+  --no-auto-cookies       Não tentar com cookies os downloads bloqueados por verificação ou idade
+  --workers N             Número de downloads simultâneos
+  --batch N               Músicas por processo do yt-dlp (0 = uma por vez)
+  --providers LISTA       Provedores de busca em ordem de reserva (youtube, youtube-music, soundcloud)
+  --include PALAVRAS      Só escolher resultados cujo título tenha todas estas palavras
+  --exclude PALAVRAS      Ignorar resultados com estas palavras (padrão: live, cover, karaoke, 8d, slowed, sped up, remix)
+  --min-duration D        Duração mínima do resultado (padrão 60s, 0 = sem limite)
+  --max-duration D        Duração máxima do resultado (padrão 15m, 0 = sem limite)
+  --task-timeout D        Tempo máximo por música (padrão 30m, 0 = sem limite)
+  --run-timeout D         Tempo máximo da execução inteira (0 = sem limite)
+  --lang CÓDIGO           Idioma da interface (%s)
+
+Códigos de saída:
+  0    tudo certo (ou nada a fazer)
+  1    alguns downloads falharam
+  2    todos os downloads falharam, ou falta uma ferramenta necessária
+  3    uso inválido ou arquivo de entrada inexistente
+  124  run_timeout atingido; "resume" continua
+  130  parado com Ctrl+C ou SIGTERM; "resume" continua
+`,
+}
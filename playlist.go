@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/leuan/leumusic-downloader/leumusic"
+)
+
+// playlistRun is a playlist expanded into its songs, in playlist order.
+type playlistRun struct {
+	info  leumusic.PlaylistInfo
+	tasks []leumusic.DownloadTask
+}
+
+// expandPlaylists replaces the playlist and channel URLs among tasks with
+// their songs. A playlist that can't be listed is reported and counted as
+// failed.
+func expandPlaylists(ctx context.Context, downloader *leumusic.Downloader, tasks []leumusic.DownloadTask, stats *DownloadStats) ([]leumusic.DownloadTask, []playlistRun) {
+	var expanded []leumusic.DownloadTask
+	var playlists []playlistRun
+	for _, task := range tasks {
+		if task.Song != "" || !leumusic.IsPlaylistURL(task.URL) {
+			expanded = append(expanded, task)
+			continue
+		}
+		fmt.Println(tr("playlist.listing", task.URL))
+		info, songs, err := downloader.ExpandPlaylist(ctx, task)
+		if err != nil && ctx.Err() != nil {
+			// Stopped: it stays in the session to be listed on resume.
+			expanded = append(expanded, task)
+			continue
+		}
+		if err != nil {
+			fmt.Println(failureMessage(task, err))
+			stats.failed++
+			failures.Add(task, err)
+			continue
+		}
+		failures.Remove(task)
+		fmt.Println(trn("playlist.found", len(songs), info.Name, len(songs)))
+		expanded = append(expanded, songs...)
+		playlists = append(playlists, playlistRun{info: info, tasks: songs})
+	}
+	return expanded, playlists
+}
+
+// importedPlaylists groups the songs of an imported song list by the
+// playlist they belong to, in the order they were listed.
+func importedPlaylists(tasks []leumusic.DownloadTask) []playlistRun {
+	var playlists []playlistRun
+	index := map[string]int{}
+	for _, t := range tasks {
+		if t.Playlist == "" {
+			continue
+		}
+		i, ok := index[t.Playlist]
+		if !ok {
+			i = len(playlists)
+			index[t.Playlist] = i
+			playlists = append(playlists, playlistRun{info: leumusic.PlaylistInfo{Name: t.Playlist}})
+		}
+		playlists[i].tasks = append(playlists[i].tasks, t)
+	}
+	return playlists
+}
+
+// writePlaylistFile writes the songs of p that are on disk, in playlist
+// order, to an .m3u8 in the playlist's folder. Songs downloaded before into
+// another folder are listed where they are.
+func writePlaylistFile(p playlistRun) {
+	folder := leumusic.Folder(".", p.info.Name, tr("folder.unnamed"))
+	var entries []leumusic.PlaylistEntry
+	for _, t := range p.tasks {
+		if rec, ok := songFile(t); ok {
+			entries = append(entries, leumusic.PlaylistEntry{Path: rec.FilePath, Title: t.Key(), Duration: rec.Duration})
+		}
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	path := filepath.Join(folder, filepath.Base(folder)+".m3u8")
+	if err := leumusic.WriteM3U8(path, entries); err != nil {
+		log.Print(tr("playlist.writeError", path, err))
+		return
+	}
+	fmt.Println(tr("playlist.saved", path, len(entries), len(p.tasks)))
+}
+
+// songFile returns the ledger record of a song whose file is on disk.
+func songFile(t leumusic.DownloadTask) (leumusic.Record, bool) {
+	rec, ok := ledger.Get(t.Artist, t.Song)
+	if !ok || rec.FilePath == "" {
+		return rec, false
+	}
+	_, err := os.Stat(rec.FilePath)
+	return rec, err == nil
+}
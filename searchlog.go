@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/leuan/leumusic-downloader/leumusic"
+)
+
+const searchLogFile = "leumusic-search.log"
+
+// openSearchLog opens the log where every search decision is appended so
+// picks can be audited later. It returns a nil logger if the file can't be
+// opened.
+func openSearchLog() (*log.Logger, func()) {
+	file, err := os.OpenFile(searchLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Print(tr("ledger.writeError", searchLogFile, err))
+		return nil, func() {}
+	}
+	return log.New(file, "", log.LstdFlags), func() { file.Close() }
+}
+
+func logFallback(logger *log.Logger, e leumusic.Event) {
+	if logger == nil || len(e.Candidates) == 0 {
+		return
+	}
+	c := e.Candidates[0]
+	logger.Printf("%s: %s #%d %s failed: %v\n", e.Task.Key(), c.Provider, c.Rank, c.URL, e.Err)
+}
+
+func logSearchDecision(logger *log.Logger, e leumusic.Event) {
+	if logger == nil || len(e.Candidates) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	chosen := e.Candidates[0]
+	if e.Err != nil {
+		fmt.Fprintf(&b, "%s [%s] -> nothing (%v)\n", e.Task.Key(), chosen.Provider, e.Err)
+	} else {
+		fmt.Fprintf(&b, "%s [%s] -> %s (score %.2f)\n", e.Task.Key(), chosen.Provider, chosen.URL, chosen.Score)
+	}
+	for i, c := range e.Candidates {
+		mark := " "
+		if i == 0 && e.Err == nil {
+			mark = "*"
+		}
+		fmt.Fprintf(&b, "  %s #%d %6.2f %8s %10d  %-30s %s", mark, c.Rank, c.Score,
+			(time.Duration(c.Duration) * time.Second).String(), c.ViewCount, c.Channel, c.Title)
+		if c.Rejected != "" {
+			fmt.Fprintf(&b, "  [%s]", c.Rejected)
+		}
+		b.WriteString("\n")
+	}
+	logger.Print(b.String())
+}
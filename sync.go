@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/leuan/leumusic-downloader/leumusic"
+)
+
+// What sync does with the songs removed from a playlist upstream. Archived
+// songs go to archiveFolder inside the playlist's folder.
+const (
+	syncArchive   = "archive"
+	syncDelete    = "delete"
+	archiveFolder = "archive"
+)
+
+var syncRemoved string
+
+var errNotPlaylist = errors.New("not a playlist URL")
+
+// syncRun is a playlist being synced: what it lists now, and what changed
+// since its manifest.
+type syncRun struct {
+	playlistRun
+	task     leumusic.DownloadTask
+	folder   string
+	manifest leumusic.Manifest
+	// added are the new songs that aren't on disk yet, or only in the
+	// archive.
+	added   []leumusic.DownloadTask
+	removed []leumusic.ManifestSong
+}
+
+// syncedPlaylists returns every playlist synced before, from the manifests
+// in the current folder.
+func syncedPlaylists() []leumusic.DownloadTask {
+	var tasks []leumusic.DownloadTask
+	for _, folder := range leumusic.SyncedFolders(".") {
+		m, err := leumusic.ReadManifest(folder)
+		if err != nil {
+			log.Print(tr("sync.openError", filepath.Join(folder, leumusic.ManifestFile), err))
+			continue
+		}
+		tasks = append(tasks, m.Task())
+	}
+	return tasks
+}
+
+// syncTargets returns the playlists given as lines, or every synced
+// playlist when there are none. A playlist synced before keeps its name
+// unless the line renames it.
+func syncTargets(lines []string) ([]leumusic.DownloadTask, error) {
+	synced := syncedPlaylists()
+	if len(lines) == 0 {
+		return synced, nil
+	}
+
+	var tasks []leumusic.DownloadTask
+	for _, line := range lines {
+		task, err := leumusic.ParseTask(line)
+		if err != nil || task.Song != "" || !leumusic.IsPlaylistURL(task.URL) {
+			fmt.Println(tr("sync.notPlaylist", line))
+			return nil, errNotPlaylist
+		}
+		for _, s := range synced {
+			if task.Playlist == "" && s.URL == task.URL {
+				task.Playlist = s.Playlist
+			}
+		}
+		tasks = append(tasks, *task)
+	}
+	return tasks, nil
+}
+
+// syncPlaylists downloads the songs added to each playlist since it was
+// last synced, archives or deletes the ones removed from it, and updates
+// its manifest and .m3u8. With dryRun it only lists the changes.
+func syncPlaylists(lines []string, dryRun bool) (DownloadStats, error) {
+	var stats DownloadStats
+	playlists, err := syncTargets(lines)
+	if err != nil {
+		return stats, err
+	}
+	if len(playlists) == 0 {
+		fmt.Println(tr("sync.none"))
+		return stats, nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	downloader := newDownloader(nil)
+	var runs []syncRun
+	for _, task := range playlists {
+		fmt.Println(tr("playlist.listing", task.URL))
+		info, songs, err := downloader.ExpandPlaylist(ctx, task)
+		if err != nil && ctx.Err() != nil {
+			stop()
+			stats.interrupted++
+			return stats, nil
+		}
+		if err != nil {
+			fmt.Println(failureMessage(task, err))
+			stats.failed++
+			failures.Add(task, err)
+			continue
+		}
+		failures.Remove(task)
+
+		run := syncRun{playlistRun: playlistRun{info: info, tasks: songs}, task: task}
+		run.folder, _ = leumusic.FindFolder(".", info.Name, tr("folder.unnamed"))
+		if run.manifest, err = leumusic.ReadManifest(run.folder); err != nil {
+			log.Print(tr("sync.openError", filepath.Join(run.folder, leumusic.ManifestFile), err))
+			stats.failed++
+			continue
+		}
+		added, removed := run.manifest.Diff(songs)
+		for _, t := range added {
+			if _, archived := archivedSong(run.folder, t); archived || !ledger.Has(t.Artist, t.Song) {
+				run.added = append(run.added, t)
+			}
+		}
+		run.removed = removed
+		printSyncChanges(run)
+		runs = append(runs, run)
+	}
+	stop()
+
+	if dryRun {
+		fmt.Println(tr("sync.dryRun"))
+		return stats, nil
+	}
+
+	var tasks []leumusic.DownloadTask
+	for _, run := range runs {
+		removeSongs(run)
+		for _, t := range run.added {
+			if !restoreSong(run.folder, t) {
+				tasks = append(tasks, t)
+			}
+		}
+	}
+	if len(tasks) > 0 {
+		failed := stats.failed
+		stats = processDownloads(tasks)
+		stats.failed += failed
+	}
+	for _, run := range runs {
+		saveSync(run)
+	}
+	saveFailures()
+	return stats, nil
+}
+
+func printSyncChanges(run syncRun) {
+	if len(run.added) == 0 && len(run.removed) == 0 {
+		fmt.Println(tr("sync.upToDate", run.info.Name))
+		return
+	}
+	fmt.Println(tr("sync.changes", run.info.Name, len(run.added), len(run.removed)))
+	for _, t := range run.added {
+		fmt.Printf("   ➕ %s\n", t.Key())
+	}
+	for _, s := range run.removed {
+		fmt.Printf("   ➖ %s\n", s.Key())
+	}
+}
+
+// removeSongs archives or deletes the files of the songs removed from the
+// playlist. Only files in the playlist's folder are touched: a song that
+// was already on disk elsewhere stays there.
+func removeSongs(run syncRun) {
+	for _, s := range run.removed {
+		if s.FilePath == "" || filepath.Dir(s.FilePath) != filepath.Clean(run.folder) {
+			continue
+		}
+		if _, err := os.Stat(s.FilePath); err != nil {
+			continue
+		}
+		key := s.Key()
+		rec, recorded := ledger.Get(s.Artist, s.Song)
+		recorded = recorded && rec.FilePath == s.FilePath
+
+		if syncRemoved == syncDelete {
+			if err := os.Remove(s.FilePath); err != nil {
+				log.Print(tr("sync.removeError", key, err))
+				continue
+			}
+			// Forgotten, so it downloads again if it comes back.
+			if recorded {
+				if err := ledger.Remove(rec.Artist, rec.Song); err != nil {
+					log.Print(tr("ledger.writeError", ledgerFile, err))
+				}
+			}
+			fmt.Println(tr("sync.deleted", key))
+			continue
+		}
+
+		archive := filepath.Join(run.folder, archiveFolder)
+		target := filepath.Join(archive, filepath.Base(s.FilePath))
+		if err := os.MkdirAll(archive, 0755); err != nil {
+			log.Print(tr("sync.removeError", key, err))
+			continue
+		}
+		if err := os.Rename(s.FilePath, target); err != nil {
+			log.Print(tr("sync.removeError", key, err))
+			continue
+		}
+		if recorded {
+			rec.FilePath = target
+			if err := ledger.Add(rec); err != nil {
+				log.Print(tr("ledger.writeError", ledgerFile, err))
+			}
+		}
+		fmt.Println(tr("sync.archived", key, archive))
+	}
+}
+
+// archivedSong returns the ledger record of a song that sync archived
+// from folder.
+func archivedSong(folder string, t leumusic.DownloadTask) (leumusic.Record, bool) {
+	rec, ok := songFile(t)
+	return rec, ok && filepath.Dir(rec.FilePath) == filepath.Join(folder, archiveFolder)
+}
+
+// restoreSong moves a song that came back to the playlist out of its
+// archive, and reports whether it did.
+func restoreSong(folder string, t leumusic.DownloadTask) bool {
+	rec, ok := archivedSong(folder, t)
+	if !ok {
+		return false
+	}
+	target := filepath.Join(folder, filepath.Base(rec.FilePath))
+	if err := os.Rename(rec.FilePath, target); err != nil {
+		log.Print(tr("sync.removeError", t.Key(), err))
+		return false
+	}
+	rec.FilePath = target
+	if err := ledger.Add(rec); err != nil {
+		log.Print(tr("ledger.writeError", ledgerFile, err))
+	}
+	fmt.Println(tr("sync.restored", t.Key(), folder))
+	return true
+}
+
+// saveSync records what the playlist holds now in its manifest and
+// .m3u8.
+func saveSync(run syncRun) {
+	folder := leumusic.Folder(".", run.info.Name, tr("folder.unnamed"))
+	run.manifest.Update(run.task, run.info, run.tasks, func(t leumusic.DownloadTask) string {
+		if rec, ok := songFile(t); ok {
+			return rec.FilePath
+		}
+		return ""
+	})
+	if err := run.manifest.Save(folder); err != nil {
+		log.Print(tr("sync.writeError", filepath.Join(folder, leumusic.ManifestFile), err))
+	}
+	writePlaylistFile(run.playlistRun)
+}